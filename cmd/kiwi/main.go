@@ -2,79 +2,115 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/maxcelant/kiwi/internal/diagnostics"
 	"github.com/maxcelant/kiwi/internal/env"
 	"github.com/maxcelant/kiwi/internal/interpreter"
 	"github.com/maxcelant/kiwi/internal/lexer"
 	"github.com/maxcelant/kiwi/internal/parser"
+	"github.com/maxcelant/kiwi/internal/resolver"
 )
 
-var lxr *lexer.Lexer
-var psr *parser.Parser
-var it *interpreter.Interpreter
+// Exit codes follow the sysexits convention
+const (
+	exitUsage    = 64 // The command line was wrong
+	exitDataErr  = 65 // The program has a syntax error
+	exitNoInput  = 66 // The script couldn't be read
+	exitSoftware = 70 // The program failed while running
+)
 
-// todo: fix me
-// func runREPL() error {
-// 	var file *os.File
-// 	var err error
-// 	file, err = os.Open("test/sample.kiwi")
-// 	if err != nil {
-// 		return err
-// 	}
-// 	defer file.Close()
+const usage = `usage: kiwi                         start the REPL
+       kiwi script.kiwi [args...]   run a script
+       kiwi - [args...]             read the program from stdin
+       kiwi -e 'source' [args...]   run a snippet
+`
 
-// 	lxr = lexer.New()
-// 	tokens := []lexer.Token{}
-// 	scanner := bufio.NewScanner(file)
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-// 	for scanner.Scan() {
-// 		lineTokens, err := lxr.ScanLine(scanner.Text())
-// 		if err != nil {
-// 			return err
-// 		}
-// 		tokens = append(tokens, lineTokens...)
-// 	}
+// Works out where the program comes from, runs it, and returns the exit code.
+// Everything after the program is handed to it in the global 'args' list.
+func run(argv []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(argv) == 0 {
+		newREPL(stdin, stdout, stderr).run()
+		return 0
+	}
 
-// 	tokens = append(tokens, lexer.Token{Type: lexer.EOF, Lexeme: "", Literal: nil, Line: lxr.Line})
-// 	fmt.Println(tokens)
+	var name, source string
+	var args []string
+	switch {
+	case argv[0] == "-h" || argv[0] == "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	case argv[0] == "-e":
+		if len(argv) < 2 {
+			fmt.Fprintf(stderr, "kiwi: -e expects a program to run\n%s", usage)
+			return exitUsage
+		}
+		name, source, args = "<eval>", argv[1], argv[2:]
+	case argv[0] == "-":
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "kiwi: error reading stdin: %s\n", err)
+			return exitNoInput
+		}
+		name, source, args = "<stdin>", string(content), argv[1:]
+	case strings.HasPrefix(argv[0], "-"):
+		fmt.Fprintf(stderr, "kiwi: unknown flag %s\n%s", argv[0], usage)
+		return exitUsage
+	default:
+		content, err := os.ReadFile(argv[0])
+		if err != nil {
+			fmt.Fprintf(stderr, "kiwi: %s\n", err)
+			return exitNoInput
+		}
+		name, source, args = argv[0], string(content), argv[1:]
+	}
 
-// 	if err := scanner.Err(); err != nil {
-// 		return err
-// 	}
-// 	return nil
-// }
+	return execute(name, source, args, stdout, stderr)
+}
 
-func run() error {
-	content, err := os.ReadFile("test/sample.kiwi")
+func execute(name string, source string, args []string, stdout io.Writer, stderr io.Writer) int {
+	tokens, err := lexer.New(source).Scan()
 	if err != nil {
-		return fmt.Errorf("error reading file: %w", err)
+		fmt.Fprint(stderr, diagnostics.Render(name, source, err))
+		return exitDataErr
 	}
 
-	lxr = lexer.New(string(content))
-	tokens, err := lxr.Scan()
+	stmts, err := parser.New(tokens).Parse()
 	if err != nil {
-		return fmt.Errorf("syntax error occurred: %w", err)
+		fmt.Fprint(stderr, diagnostics.Render(name, source, err))
+		return exitDataErr
 	}
 
-	psr = parser.New(tokens)
-	stmts, err := psr.Parse()
+	locals, err := resolver.New().Resolve(stmts)
 	if err != nil {
-		return fmt.Errorf("parse error occurred: %w", err)
+		fmt.Fprint(stderr, diagnostics.Render(name, source, err))
+		return exitDataErr
 	}
 
-	it = interpreter.New(stmts, env.New(nil))
-	it.Interpret()
-
-	return nil
+	environment := env.New(nil)
+	environment.Define("args", scriptArgs(args))
+	it := interpreter.New(stmts, environment)
+	it.Resolve(locals)
+	it.SetFile(name)
+	it.SetOutput(stdout)
+	if err := it.Interpret(); err != nil {
+		// Runtime errors already say where they happened and how the program got there
+		fmt.Fprintln(stderr, err)
+		return exitSoftware
+	}
+	return 0
 }
 
-func main() {
-	if err := run(); err != nil {
-		log.Fatal(err)
+func scriptArgs(args []string) *interpreter.List {
+	elements := make([]any, len(args))
+	for i, arg := range args {
+		elements[i] = arg
 	}
-	// if err := runREPL(); err != nil {
-	// 	log.Fatal(err)
-	// }
+	return interpreter.NewList(elements)
 }
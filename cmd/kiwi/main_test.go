@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CLI", func() {
+	var out, errOut *bytes.Buffer
+
+	kiwi := func(stdin string, argv ...string) int {
+		out, errOut = &bytes.Buffer{}, &bytes.Buffer{}
+		return run(argv, strings.NewReader(stdin), out, errOut)
+	}
+
+	script := func(source string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "script.kiwi")
+		Expect(os.WriteFile(path, []byte(source), 0o644)).To(Succeed())
+		return path
+	}
+
+	Describe("running a script", func() {
+		It("runs the file and exits with 0", func() {
+			Expect(kiwi("", script("print 1 + 2;"))).To(Equal(0))
+			Expect(out.String()).To(Equal("3\n"))
+			Expect(errOut.String()).To(BeEmpty())
+		})
+
+		It("hands the rest of the arguments to the program as args", func() {
+			Expect(kiwi("", script("print args; print len(args);"), "first", "second")).To(Equal(0))
+			Expect(out.String()).To(Equal("[\"first\", \"second\"]\n2\n"))
+		})
+
+		It("gives the program an empty args list when there are none", func() {
+			Expect(kiwi("", script("print args;"))).To(Equal(0))
+			Expect(out.String()).To(Equal("[]\n"))
+		})
+
+		It("exits with 66 when the file can't be read", func() {
+			Expect(kiwi("", "missing.kiwi")).To(Equal(exitNoInput))
+			Expect(errOut.String()).To(ContainSubstring("no such file or directory"))
+		})
+	})
+
+	Describe("reading the program from stdin", func() {
+		It("runs what it reads", func() {
+			Expect(kiwi("print \"from stdin\"; print args;", "-", "x")).To(Equal(0))
+			Expect(out.String()).To(Equal("from stdin\n[\"x\"]\n"))
+		})
+
+		It("reports errors against <stdin>", func() {
+			Expect(kiwi("print 1 - \"a\";", "-")).To(Equal(exitSoftware))
+			Expect(errOut.String()).To(Equal("<stdin>:1:9: operands must be a number for subtract operation\n"))
+		})
+	})
+
+	Describe("running a snippet with -e", func() {
+		It("runs the snippet", func() {
+			Expect(kiwi("", "-e", "print 1 + 2; print args;", "a")).To(Equal(0))
+			Expect(out.String()).To(Equal("3\n[\"a\"]\n"))
+		})
+
+		It("exits with 64 when the snippet is missing", func() {
+			Expect(kiwi("", "-e")).To(Equal(exitUsage))
+			Expect(errOut.String()).To(ContainSubstring("-e expects a program to run"))
+		})
+	})
+
+	DescribeTable("exits with the sysexits code for each kind of failure",
+		func(source string, code int, message string) {
+			Expect(kiwi("", "-e", source)).To(Equal(code))
+			Expect(errOut.String()).To(ContainSubstring(message))
+		},
+		Entry("a character the lexer doesn't know", "print 1 @ 2;", exitDataErr, "unexpected character '@'"),
+		Entry("a syntax error", "print 1 +;", exitDataErr, "--> <eval>:1:10"),
+		Entry("a resolver error", "{ var a = 1; var a = 2; }", exitDataErr, "already a variable named 'a' in this scope"),
+		Entry("a runtime error", "print missing;", exitSoftware, "<eval>:1:7: undefined variable: 'missing'"),
+	)
+
+	It("exits with 64 for a flag it doesn't know", func() {
+		Expect(kiwi("", "-x")).To(Equal(exitUsage))
+		Expect(errOut.String()).To(ContainSubstring("unknown flag -x"))
+	})
+
+	It("prints the usage for -h", func() {
+		Expect(kiwi("", "-h")).To(Equal(0))
+		Expect(out.String()).To(ContainSubstring("usage: kiwi"))
+	})
+
+	It("starts the REPL when there are no arguments", func() {
+		Expect(kiwi("1 + 2\n")).To(Equal(0))
+		Expect(out.String()).To(Equal("> 3\n> \n"))
+	})
+})
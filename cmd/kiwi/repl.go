@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/maxcelant/kiwi/internal/diagnostics"
+	"github.com/maxcelant/kiwi/internal/env"
+	"github.com/maxcelant/kiwi/internal/interpreter"
+	"github.com/maxcelant/kiwi/internal/lexer"
+	"github.com/maxcelant/kiwi/internal/parser"
+	"github.com/maxcelant/kiwi/internal/resolver"
+	"github.com/maxcelant/kiwi/internal/stmt"
+)
+
+const replHelp = `Enter kiwi statements to run them, or an expression to see its value.
+Input keeps going onto the next line while a brace, paren or bracket is open.
+
+  :help         show this message
+  :env          list the variables that have been defined
+  :load <file>  run a file, keeping what it defines
+  :quit         leave the REPL, as does Ctrl-D
+`
+
+// Every input runs against the same interpreter and environment, so variables,
+// functions and classes stick around between lines
+type repl struct {
+	it          *interpreter.Interpreter
+	environment *env.Environment
+	in          *bufio.Scanner
+	out         io.Writer
+	errOut      io.Writer
+}
+
+func newREPL(in io.Reader, out io.Writer, errOut io.Writer) *repl {
+	environment := env.New(nil)
+	environment.Define("args", scriptArgs(nil))
+	it := interpreter.New(nil, environment)
+	it.SetOutput(out)
+	return &repl{
+		it:          it,
+		environment: environment,
+		in:          bufio.NewScanner(in),
+		out:         out,
+		errOut:      errOut,
+	}
+}
+
+func (r *repl) run() {
+	for {
+		source, ok := r.read()
+		if !ok {
+			fmt.Fprintln(r.out)
+			return
+		}
+		line := strings.TrimSpace(source)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			if quit := r.command(line); quit {
+				return
+			}
+			continue
+		}
+		r.eval("<repl>", source, true)
+	}
+}
+
+// Reads one input, which carries on over as many lines as it takes to close
+// everything that was opened. Returns false once the input runs out.
+func (r *repl) read() (string, bool) {
+	var source strings.Builder
+	prompt := "> "
+	for {
+		fmt.Fprint(r.out, prompt)
+		if !r.in.Scan() {
+			return source.String(), source.Len() > 0
+		}
+		source.WriteString(r.in.Text())
+		source.WriteByte('\n')
+		if !incomplete(source.String()) {
+			return source.String(), true
+		}
+		prompt = "... "
+	}
+}
+
+// Whether the source is still waiting on a closing brace, paren, bracket or quote
+func incomplete(source string) bool {
+	tokens, err := lexer.New(source).Scan()
+	if err != nil {
+		for _, d := range diagnostics.From(err) {
+			if strings.HasPrefix(d.Message, "unterminated") {
+				return true
+			}
+		}
+		return false
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		switch token.Type {
+		case lexer.LEFT_BRACE, lexer.LEFT_PAREN, lexer.LEFT_BRACKET:
+			depth += 1
+		case lexer.RIGHT_BRACE, lexer.RIGHT_PAREN, lexer.RIGHT_BRACKET:
+			depth -= 1
+		}
+	}
+	return depth > 0
+}
+
+func (r *repl) command(line string) (quit bool) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case ":help":
+		fmt.Fprint(r.out, replHelp)
+	case ":env":
+		r.dumpEnv()
+	case ":load":
+		if arg == "" {
+			fmt.Fprintln(r.errOut, ":load expects a file to run")
+			return false
+		}
+		content, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintf(r.errOut, "kiwi: %s\n", err)
+			return false
+		}
+		r.eval(arg, string(content), false)
+	case ":quit":
+		return true
+	default:
+		fmt.Fprintf(r.errOut, "unknown command %s, try :help\n", name)
+	}
+	return false
+}
+
+// Lists the globals in name order, leaving out the natives that every session starts with
+func (r *repl) dumpEnv() {
+	names := []string{}
+	for name, value := range r.environment.Values {
+		if _, ok := value.(*interpreter.NativeFunction); ok {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(r.out, "%s = %s\n", name, interpreter.Stringify(r.environment.Values[name]))
+	}
+}
+
+// Runs the source against the session, printing any errors rather than giving
+// up on them. When echo is set and the input is a lone expression, its value
+// is printed too, and the trailing ';' can be left off.
+func (r *repl) eval(name string, source string, echo bool) {
+	stmts, err := r.parse(source, echo)
+	if err != nil {
+		fmt.Fprint(r.errOut, diagnostics.Render(name, source, err))
+		return
+	}
+
+	locals, err := resolver.New().Resolve(stmts)
+	if err != nil {
+		fmt.Fprint(r.errOut, diagnostics.Render(name, source, err))
+		return
+	}
+	r.it.Resolve(locals)
+	r.it.SetFile(name)
+
+	if expression, ok := loneExpression(stmts); ok && echo {
+		value, err := r.it.Evaluate(expression.Expression)
+		if err != nil {
+			fmt.Fprintln(r.errOut, err)
+			return
+		}
+		if value != nil {
+			fmt.Fprintln(r.out, interpreter.Stringify(value))
+		}
+		return
+	}
+
+	for _, st := range stmts {
+		if err := r.it.Execute(st); err != nil {
+			fmt.Fprintln(r.errOut, err)
+			return
+		}
+	}
+}
+
+func (r *repl) parse(source string, echo bool) ([]stmt.Stmt, error) {
+	tokens, err := lexer.New(source).Scan()
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := parser.New(tokens).Parse()
+	if err == nil || !echo {
+		return stmts, err
+	}
+
+	// `1 + 2` is as good as `1 + 2;` at the prompt, so give a bare expression
+	// another go with the semicolon it was missing
+	retry, retryErr := lexer.New(strings.TrimRight(source, " \t\r\n") + ";").Scan()
+	if retryErr != nil {
+		return nil, err
+	}
+	retried, retryErr := parser.New(retry).Parse()
+	if _, ok := loneExpression(retried); retryErr != nil || !ok {
+		return nil, err
+	}
+	return retried, nil
+}
+
+func loneExpression(stmts []stmt.Stmt) (stmt.Expression, bool) {
+	if len(stmts) != 1 {
+		return stmt.Expression{}, false
+	}
+	expression, ok := stmts[0].(stmt.Expression)
+	return expression, ok
+}
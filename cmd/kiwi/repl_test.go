@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestKiwi(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Kiwi Suite")
+}
+
+var _ = Describe("REPL", func() {
+	var out, errOut *bytes.Buffer
+
+	session := func(lines ...string) {
+		out, errOut = &bytes.Buffer{}, &bytes.Buffer{}
+		in := strings.NewReader(strings.Join(lines, "\n") + "\n")
+		newREPL(in, out, errOut).run()
+	}
+
+	It("keeps variables and functions between inputs", func() {
+		session("var a = 1;", "fn inc(x) { return x + 1; }", "inc(a)")
+		Expect(out.String()).To(ContainSubstring("2\n"))
+		Expect(errOut.String()).To(BeEmpty())
+	})
+
+	It("prints the value of a bare expression, with or without a semicolon", func() {
+		session("1 + 2;", "\"kiwi\"", "[1, 2]")
+		Expect(out.String()).To(ContainSubstring("3\n"))
+		Expect(out.String()).To(ContainSubstring("kiwi\n"))
+		Expect(out.String()).To(ContainSubstring("[1, 2]\n"))
+	})
+
+	It("doesn't print anything for an expression without a value", func() {
+		session("var a = 1;", "a = 2")
+		Expect(out.String()).To(Equal("> > > \n"))
+	})
+
+	It("keeps reading while braces, parens or brackets are open", func() {
+		session("fn add(x, y) {", "  return x + y;", "}", "add(", "1, 2", ")")
+		Expect(out.String()).To(ContainSubstring("... ... > ... ... 3\n"))
+		Expect(errOut.String()).To(BeEmpty())
+	})
+
+	It("reports errors and carries on", func() {
+		session("var = 1;", "missing;", "1 - \"x\"", "4")
+		Expect(errOut.String()).To(ContainSubstring("error: expect variable name"))
+		Expect(errOut.String()).To(ContainSubstring("<repl>:1:1: undefined variable: 'missing'"))
+		Expect(errOut.String()).To(ContainSubstring("<repl>:1:3: operands must be a number for subtract operation"))
+		Expect(out.String()).To(ContainSubstring("4\n"))
+	})
+
+	It("writes print statements to the session's output", func() {
+		session("print \"hi\";")
+		Expect(out.String()).To(Equal("> hi\n> \n"))
+	})
+
+	It("stops at :quit", func() {
+		session(":quit", "print 1;")
+		Expect(out.String()).To(Equal("> "))
+	})
+
+	Describe("commands", func() {
+		It("lists the variables that have been defined, without the natives", func() {
+			session("var b = \"two\";", "var a = 1;", ":env")
+			Expect(out.String()).To(ContainSubstring("a = 1\nargs = []\nb = two\n"))
+			Expect(out.String()).ToNot(ContainSubstring("len"))
+		})
+
+		It("prints the help", func() {
+			session(":help")
+			Expect(out.String()).To(ContainSubstring(":load <file>"))
+		})
+
+		It("loads a file into the session", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "lib.kiwi")
+			Expect(os.WriteFile(path, []byte("fn double(x) {\n  return x * 2;\n}\n"), 0o644)).To(Succeed())
+			session(":load "+path, "double(21)")
+			Expect(out.String()).To(ContainSubstring("42\n"))
+		})
+
+		It("reports a file that can't be loaded", func() {
+			session(":load missing.kiwi")
+			Expect(errOut.String()).To(ContainSubstring("no such file or directory"))
+		})
+
+		It("reports commands it doesn't know", func() {
+			session(":nope")
+			Expect(errOut.String()).To(ContainSubstring("unknown command :nope"))
+		})
+	})
+})
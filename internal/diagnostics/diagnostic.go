@@ -0,0 +1,83 @@
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	case Note:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// Where in the source a diagnostic points. Line and Column are 1-based, with
+// the column counted in runes, and Start and End are the byte offsets of the
+// offending text, the same way tokens record them.
+type Span struct {
+	Line   int64
+	Column int64
+	Start  int64
+	End    int64
+}
+
+// A problem found in a kiwi program. Notes are extra lines of context that are
+// printed below the snippet, such as a hint on how to fix it.
+type Diagnostic struct {
+	Severity Severity
+	Span     Span
+	Message  string
+	Notes    []string
+}
+
+func New(severity Severity, span Span, message string, notes ...string) *Diagnostic {
+	return &Diagnostic{
+		Severity: severity,
+		Span:     span,
+		Message:  message,
+		Notes:    notes,
+	}
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", d.Span.Line, d.Span.Column, d.Message)
+}
+
+// Every diagnostic found in a single pass, in source order
+type List []*Diagnostic
+
+func (l List) Error() string {
+	messages := make([]string, len(l))
+	for i, d := range l {
+		messages[i] = d.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Pulls the diagnostics out of an error, which may be a single Diagnostic or a
+// List, and may have been wrapped along the way. Returns nil if there are none.
+func From(err error) List {
+	var list List
+	if errors.As(err, &list) {
+		return list
+	}
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return List{d}
+	}
+	return nil
+}
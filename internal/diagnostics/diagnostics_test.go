@@ -0,0 +1,92 @@
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiagnostics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diagnostics Suite")
+}
+
+var _ = Describe("Diagnostics", func() {
+	Describe("Error", func() {
+		It("prefixes the message with where it happened", func() {
+			d := New(Error, Span{Line: 3, Column: 7, Start: 20, End: 21}, "invalid assignment target")
+			Expect(d.Error()).To(Equal("line 3, column 7: invalid assignment target"))
+		})
+
+		It("joins every diagnostic in a list onto its own line", func() {
+			list := List{
+				New(Error, Span{Line: 1, Column: 5}, "expect variable name"),
+				New(Error, Span{Line: 2, Column: 10}, "; expected expression"),
+			}
+			Expect(list.Error()).To(Equal("line 1, column 5: expect variable name\nline 2, column 10: ; expected expression"))
+		})
+	})
+
+	Describe("From", func() {
+		It("finds a diagnostic that has been wrapped", func() {
+			d := New(Error, Span{Line: 1, Column: 1}, "undefined variable: 'a'")
+			Expect(From(fmt.Errorf("failed: %w", d))).To(Equal(List{d}))
+		})
+
+		It("returns nil for errors that don't point anywhere", func() {
+			Expect(From(errors.New("error reading file"))).To(BeNil())
+		})
+	})
+
+	Describe("Render", func() {
+		It("underlines the span beneath the line of source", func() {
+			source := "var a = 1;\nprint a +;\n"
+			d := New(Error, Span{Line: 2, Column: 10, Start: 20, End: 21}, "; expected expression")
+			Expect(d.Render("script.kiwi", source)).To(Equal("" +
+				"error: ; expected expression\n" +
+				" --> script.kiwi:2:10\n" +
+				"  |\n" +
+				"2 | print a +;\n" +
+				"  |          ^\n"))
+		})
+
+		It("underlines every rune of a multi-byte lexeme", func() {
+			source := `print "héllo" - 1;`
+			d := New(Error, Span{Line: 1, Column: 15, Start: 15, End: 16}, "operands must be a number for subtract operation")
+			Expect(d.Render("", source)).To(ContainSubstring("1 | print \"héllo\" - 1;\n  |               ^\n"))
+
+			d = New(Error, Span{Line: 1, Column: 7, Start: 6, End: 14}, "not a number")
+			Expect(d.Render("", source)).To(ContainSubstring("  |       ^^^^^^^\n"))
+		})
+
+		It("keeps tabs in the padding so the caret lines up", func() {
+			source := "\tprint @;"
+			d := New(Error, Span{Line: 1, Column: 8, Start: 7, End: 8}, "unexpected character '@'")
+			Expect(d.Render("", source)).To(ContainSubstring("1 | \tprint @;\n  | \t      ^\n"))
+		})
+
+		It("widens the gutter for longer line numbers and prints notes", func() {
+			source := "\n\n\n\n\n\n\n\n\nvar = 1;"
+			d := New(Warning, Span{Line: 10, Column: 5, Start: 13, End: 14}, "expect variable name", "variables need a name")
+			Expect(d.Render("", source)).To(Equal("" +
+				"warning: expect variable name\n" +
+				"  --> line 10, column 5\n" +
+				"   |\n" +
+				"10 | var = 1;\n" +
+				"   |     ^\n" +
+				"   = note: variables need a name\n"))
+		})
+
+		It("leaves out the snippet when the line isn't in the source", func() {
+			d := New(Error, Span{Line: 4, Column: 1}, "unterminated string")
+			Expect(d.Render("", "print 1;")).To(Equal("error: unterminated string\n --> line 4, column 1\n"))
+		})
+
+		It("falls back to the plain message for other errors", func() {
+			Expect(Render("", "", errors.New("error reading file"))).To(Equal("error reading file\n"))
+		})
+	})
+})
@@ -0,0 +1,96 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Renders the diagnostic along with the line of source it points at, and a
+// caret underline beneath the offending text. The location uses the same
+// `file:line:col` form as runtime errors, or just the line and column when
+// there is no file name:
+//
+//	error: expect variable name
+//	 --> script.kiwi:1:5
+//	  |
+//	1 | var = 1;
+//	  |     ^
+//	  = note: ...
+func (d *Diagnostic) Render(name string, source string) string {
+	var b strings.Builder
+	gutter := strings.Repeat(" ", len(strconv.FormatInt(d.Span.Line, 10)))
+
+	fmt.Fprintf(&b, "%s: %s\n", d.Severity, d.Message)
+	if name == "" {
+		fmt.Fprintf(&b, "%s--> line %d, column %d\n", gutter, d.Span.Line, d.Span.Column)
+	} else {
+		fmt.Fprintf(&b, "%s--> %s:%d:%d\n", gutter, name, d.Span.Line, d.Span.Column)
+	}
+
+	if line, ok := sourceLine(source, d.Span.Line); ok {
+		fmt.Fprintf(&b, "%s |\n", gutter)
+		fmt.Fprintf(&b, "%d | %s\n", d.Span.Line, line)
+		fmt.Fprintf(&b, "%s | %s\n", gutter, underline(line, d.Span))
+	}
+	for _, note := range d.Notes {
+		fmt.Fprintf(&b, "%s = note: %s\n", gutter, note)
+	}
+	return b.String()
+}
+
+// Renders each diagnostic in turn, separated by a blank line
+func (l List) Render(name string, source string) string {
+	rendered := make([]string, len(l))
+	for i, d := range l {
+		rendered[i] = d.Render(name, source)
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// Renders the diagnostics carried by err, or falls back to its plain message
+// for errors that don't point anywhere in the source
+func Render(name string, source string, err error) string {
+	if list := From(err); list != nil {
+		return list.Render(name, source)
+	}
+	return err.Error() + "\n"
+}
+
+func sourceLine(source string, number int64) (string, bool) {
+	if number < 1 {
+		return "", false
+	}
+	lines := strings.Split(source, "\n")
+	if number > int64(len(lines)) {
+		return "", false
+	}
+	return strings.TrimRight(lines[number-1], "\r"), true
+}
+
+// Lines the caret up under the span's column, keeping any tabs from the line so
+// the padding is as wide as the text above it, then underlines as many runes as
+// the span covers on this line, always at least one.
+func underline(line string, span Span) string {
+	var b strings.Builder
+	rest := line
+	for col := int64(1); col < span.Column && rest != ""; col++ {
+		r, size := utf8.DecodeRuneInString(rest)
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+		rest = rest[size:]
+	}
+
+	width := 0
+	for remaining := span.End - span.Start; remaining > 0 && rest != ""; width++ {
+		_, size := utf8.DecodeRuneInString(rest)
+		rest = rest[size:]
+		remaining -= int64(size)
+	}
+	b.WriteString(strings.Repeat("^", max(width, 1)))
+	return b.String()
+}
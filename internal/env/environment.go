@@ -3,6 +3,7 @@ package env
 import (
 	"fmt"
 
+	"github.com/maxcelant/kiwi/internal/diagnostics"
 	"github.com/maxcelant/kiwi/internal/lexer"
 )
 
@@ -30,7 +31,7 @@ func (e *Environment) Assign(name lexer.Token, value any) error {
 		return e.Parent.Assign(name, value)
 	}
 
-	return fmt.Errorf("undefined variable: '%s'", name.Lexeme)
+	return undefined(name)
 }
 
 func (e *Environment) Define(name string, value any) {
@@ -46,5 +47,31 @@ func (e *Environment) Get(token lexer.Token) (any, error) {
 		return e.Parent.Get(token)
 	}
 
-	return nil, fmt.Errorf("undefined variable: %s", token.Lexeme)
+	return nil, undefined(token)
+}
+
+// The resolver already knows how many scopes away a local variable lives, so
+// these skip the name lookups on every environment along the way
+func (e *Environment) GetAt(distance int, name lexer.Token) (any, error) {
+	value, ok := e.Ancestor(distance).Values[name.Lexeme]
+	if !ok {
+		return nil, undefined(name)
+	}
+	return value, nil
+}
+
+func (e *Environment) AssignAt(distance int, name lexer.Token, value any) {
+	e.Ancestor(distance).Values[name.Lexeme] = value
+}
+
+func (e *Environment) Ancestor(distance int) *Environment {
+	environment := e
+	for i := 0; i < distance; i++ {
+		environment = environment.Parent
+	}
+	return environment
+}
+
+func undefined(name lexer.Token) error {
+	return diagnostics.New(diagnostics.Error, name.Span(), fmt.Sprintf("undefined variable: '%s'", name.Lexeme))
 }
@@ -7,7 +7,7 @@ type Assign struct {
 	Value Expr
 }
 
-func (a Assign) Accept(v Visitor) (any, error) {
+func (a *Assign) Accept(v Visitor) (any, error) {
 	val, err := v.VisitAssign(a)
 	if err != nil {
 		return nil, err
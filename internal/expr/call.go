@@ -0,0 +1,17 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type Call struct {
+	Callee    Expr
+	Paren     lexer.Token // The closing ')', used to report errors at the call site
+	Arguments []Expr
+}
+
+func (c Call) Accept(v Visitor) (any, error) {
+	val, err := v.VisitCall(c)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
@@ -0,0 +1,16 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type Get struct {
+	Object Expr
+	Name   lexer.Token
+}
+
+func (g Get) Accept(v Visitor) (any, error) {
+	val, err := v.VisitGet(g)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
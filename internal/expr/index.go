@@ -0,0 +1,17 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type Index struct {
+	Object  Expr
+	Bracket lexer.Token // The closing ']', used to report errors at the index site
+	Index   Expr
+}
+
+func (i Index) Accept(v Visitor) (any, error) {
+	val, err := v.VisitIndex(i)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
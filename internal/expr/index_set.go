@@ -0,0 +1,18 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type IndexSet struct {
+	Object  Expr
+	Bracket lexer.Token
+	Index   Expr
+	Value   Expr
+}
+
+func (i IndexSet) Accept(v Visitor) (any, error) {
+	val, err := v.VisitIndexSet(i)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
@@ -0,0 +1,15 @@
+package expr
+
+// A string with embedded expressions, such as `"hi ${name}"`. Parts alternates
+// between the literal pieces of the string and the expressions between them.
+type Interpolation struct {
+	Parts []Expr
+}
+
+func (i Interpolation) Accept(v Visitor) (any, error) {
+	val, err := v.VisitInterpolation(i)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
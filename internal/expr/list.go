@@ -0,0 +1,13 @@
+package expr
+
+type List struct {
+	Elements []Expr
+}
+
+func (l List) Accept(v Visitor) (any, error) {
+	val, err := v.VisitList(l)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
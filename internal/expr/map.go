@@ -0,0 +1,19 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+// Keys and values are kept side by side so that entries evaluate in the order
+// they were written
+type Map struct {
+	Brace  lexer.Token // The opening '{', used to report errors in the literal
+	Keys   []Expr
+	Values []Expr
+}
+
+func (m Map) Accept(v Visitor) (any, error) {
+	val, err := v.VisitMap(m)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
@@ -0,0 +1,17 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type Set struct {
+	Object Expr
+	Name   lexer.Token
+	Value  Expr
+}
+
+func (s Set) Accept(v Visitor) (any, error) {
+	val, err := v.VisitSet(s)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
@@ -0,0 +1,19 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+// Either bound can be left out, as in `xs[:2]` or `xs[1:]`, in which case it is nil
+type Slice struct {
+	Object  Expr
+	Bracket lexer.Token
+	Start   Expr
+	End     Expr
+}
+
+func (s Slice) Accept(v Visitor) (any, error) {
+	val, err := v.VisitSlice(s)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
@@ -0,0 +1,17 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+// Like Variable, this is always used as a pointer so the resolver can bind it
+type Super struct {
+	Keyword lexer.Token
+	Method  lexer.Token
+}
+
+func (s *Super) Accept(v Visitor) (any, error) {
+	val, err := v.VisitSuper(s)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
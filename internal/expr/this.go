@@ -0,0 +1,16 @@
+package expr
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+// Like Variable, this is always used as a pointer so the resolver can bind it
+type This struct {
+	Keyword lexer.Token
+}
+
+func (t *This) Accept(v Visitor) (any, error) {
+	val, err := v.VisitThis(t)
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
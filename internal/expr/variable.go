@@ -2,11 +2,13 @@ package expr
 
 import "github.com/maxcelant/kiwi/internal/lexer"
 
+// Variable and Assign are always used as pointers, since the resolver keys the
+// scope depth of each variable access on the identity of its node
 type Variable struct {
 	Name lexer.Token
 }
 
-func (vr Variable) Accept(v Visitor) (any, error) {
+func (vr *Variable) Accept(v Visitor) (any, error) {
 	val, err := v.VisitVariable(vr)
 	if err != nil {
 		return nil, err
@@ -6,6 +6,17 @@ type Visitor interface {
 	VisitLogical(Expr) (any, error)
 	VisitBinary(Expr) (any, error)
 	VisitUnary(Expr) (any, error)
+	VisitCall(Expr) (any, error)
+	VisitGet(Expr) (any, error)
+	VisitSet(Expr) (any, error)
+	VisitIndex(Expr) (any, error)
+	VisitIndexSet(Expr) (any, error)
+	VisitSlice(Expr) (any, error)
+	VisitThis(Expr) (any, error)
+	VisitSuper(Expr) (any, error)
 	VisitPrimary(Expr) (any, error)
+	VisitList(Expr) (any, error)
+	VisitMap(Expr) (any, error)
+	VisitInterpolation(Expr) (any, error)
 	VisitGrouping(Expr) (any, error)
 }
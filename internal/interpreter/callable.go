@@ -0,0 +1,71 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maxcelant/kiwi/internal/env"
+	"github.com/maxcelant/kiwi/internal/stmt"
+)
+
+// Anything that can be invoked with `callee(args...)` from a kiwi program
+type Callable interface {
+	Arity() int
+	Call(it *Interpreter, args []any) (any, error)
+}
+
+// A user defined function, created when the interpreter reaches its declaration.
+// It holds on to the environment it was declared in, so the variables around it
+// stay alive for as long as the function does.
+type Function struct {
+	declaration   stmt.Function
+	closure       *env.Environment
+	isInitializer bool
+}
+
+func NewFunction(declaration stmt.Function, closure *env.Environment, isInitializer bool) *Function {
+	return &Function{
+		declaration:   declaration,
+		closure:       closure,
+		isInitializer: isInitializer,
+	}
+}
+
+// Creates a copy of the method whose closure has 'this' bound to the instance,
+// which is what lets `var m = obj.method; m();` still know about obj
+func (f *Function) Bind(instance *Instance) *Function {
+	environment := env.New(f.closure)
+	environment.Define("this", instance)
+	return NewFunction(f.declaration, environment, f.isInitializer)
+}
+
+func (f *Function) Arity() int {
+	return len(f.declaration.Params)
+}
+
+func (f *Function) Call(it *Interpreter, args []any) (any, error) {
+	// Each call gets its own environment so recursive calls don't clobber each other's parameters
+	environment := env.New(f.closure)
+	for i, param := range f.declaration.Params {
+		environment.Define(param.Lexeme, args[i])
+	}
+
+	err := it.executeBlock(f.declaration.Body, environment)
+	var ret *returnSignal
+	if err != nil && !errors.As(err, &ret) {
+		return nil, err
+	}
+	// An initializer always hands back the instance, even when called directly
+	// or when it exits early with a bare 'return'
+	if f.isInitializer {
+		return f.closure.Values["this"], nil
+	}
+	if ret != nil {
+		return ret.value, nil
+	}
+	return nil, nil
+}
+
+func (f *Function) String() string {
+	return fmt.Sprintf("<fn %s>", f.declaration.Name.Lexeme)
+}
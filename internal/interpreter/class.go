@@ -0,0 +1,87 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/maxcelant/kiwi/internal/lexer"
+)
+
+// The runtime representation of a class declaration. Calling it creates a new instance.
+type Class struct {
+	name       string
+	superclass *Class // Can be null
+	methods    map[string]*Function
+}
+
+func NewClass(name string, superclass *Class, methods map[string]*Function) *Class {
+	return &Class{
+		name:       name,
+		superclass: superclass,
+		methods:    methods,
+	}
+}
+
+// Looks through the class and then up its superclass chain, so the closest
+// override of a method always wins
+func (c *Class) FindMethod(name string) (*Function, bool) {
+	if method, ok := c.methods[name]; ok {
+		return method, true
+	}
+	if c.superclass != nil {
+		return c.superclass.FindMethod(name)
+	}
+	return nil, false
+}
+
+// A class takes the same arguments as its initializer, or none if it doesn't have one
+func (c *Class) Arity() int {
+	if init, ok := c.FindMethod("init"); ok {
+		return init.Arity()
+	}
+	return 0
+}
+
+func (c *Class) Call(it *Interpreter, args []any) (any, error) {
+	instance := NewInstance(c)
+	if init, ok := c.FindMethod("init"); ok {
+		if _, err := init.Bind(instance).Call(it, args); err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+func (c *Class) String() string {
+	return c.name
+}
+
+type Instance struct {
+	class  *Class
+	fields map[string]any
+}
+
+func NewInstance(class *Class) *Instance {
+	return &Instance{
+		class:  class,
+		fields: make(map[string]any),
+	}
+}
+
+// Fields shadow methods, so a method can be swapped out on a single instance
+func (i *Instance) Get(name lexer.Token) (any, error) {
+	if value, ok := i.fields[name.Lexeme]; ok {
+		return value, nil
+	}
+	if method, ok := i.class.FindMethod(name.Lexeme); ok {
+		return method.Bind(i), nil
+	}
+	return nil, fmt.Errorf("undefined property: '%s'", name.Lexeme)
+}
+
+func (i *Instance) Set(name lexer.Token, value any) {
+	i.fields[name.Lexeme] = value
+}
+
+func (i *Instance) String() string {
+	return fmt.Sprintf("<%s instance>", i.class.name)
+}
@@ -0,0 +1,21 @@
+package interpreter
+
+import "errors"
+
+// Break and continue are signalled by returning these errors from Execute, so
+// they unwind through any nested blocks until the enclosing loop catches them.
+// The parser guarantees they never appear outside of a loop body.
+var (
+	errBreak    = errors.New("'break' used outside of a loop")
+	errContinue = errors.New("'continue' used outside of a loop")
+)
+
+// Return works the same way, except it carries the returned value up to the
+// function call that is unwinding
+type returnSignal struct {
+	value any
+}
+
+func (r *returnSignal) Error() string {
+	return "'return' used outside of a function"
+}
@@ -0,0 +1,192 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+const minDequeCapacity = 8
+
+// A double-ended queue backed by a ring buffer. head is the index of the front
+// element and the rest follow it, wrapping around the end of buf. Pushing and
+// popping at either end is O(1), apart from the occasional resize when full.
+type Deque struct {
+	buf  []any
+	head int
+	size int
+}
+
+func NewDeque() *Deque {
+	return &Deque{buf: make([]any, minDequeCapacity)}
+}
+
+func (d *Deque) Len() int {
+	return d.size
+}
+
+// Maps a logical position, where 0 is the front, onto an index in buf
+func (d *Deque) slot(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+func (d *Deque) grow() {
+	buf := make([]any, len(d.buf)*2)
+	for i := 0; i < d.size; i++ {
+		buf[i] = d.buf[d.slot(i)]
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+func (d *Deque) PushBack(value any) {
+	if d.size == len(d.buf) {
+		d.grow()
+	}
+	d.buf[d.slot(d.size)] = value
+	d.size++
+}
+
+func (d *Deque) PushFront(value any) {
+	if d.size == len(d.buf) {
+		d.grow()
+	}
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = value
+	d.size++
+}
+
+func (d *Deque) PopFront() (any, error) {
+	if d.size == 0 {
+		return nil, fmt.Errorf("cannot pop from an empty deque")
+	}
+	value := d.buf[d.head]
+	d.buf[d.head] = nil // Don't hold on to values that have been popped
+	d.head = d.slot(1)
+	d.size--
+	return value, nil
+}
+
+func (d *Deque) PopBack() (any, error) {
+	if d.size == 0 {
+		return nil, fmt.Errorf("cannot pop from an empty deque")
+	}
+	last := d.slot(d.size - 1)
+	value := d.buf[last]
+	d.buf[last] = nil
+	d.size--
+	return value, nil
+}
+
+func (d *Deque) PeekFront() (any, error) {
+	if d.size == 0 {
+		return nil, fmt.Errorf("cannot peek at an empty deque")
+	}
+	return d.buf[d.head], nil
+}
+
+func (d *Deque) PeekBack() (any, error) {
+	if d.size == 0 {
+		return nil, fmt.Errorf("cannot peek at an empty deque")
+	}
+	return d.buf[d.slot(d.size-1)], nil
+}
+
+// Indexing follows the same rules as lists, so `d[0]` is the front and `d[-1]`
+// is the back. This is what lets a program walk through a deque with a for loop.
+func (d *Deque) Get(index any) (any, error) {
+	i, err := position(index, d.size, "deque")
+	if err != nil {
+		return nil, err
+	}
+	return d.buf[d.slot(i)], nil
+}
+
+func (d *Deque) Set(index any, value any) error {
+	i, err := position(index, d.size, "deque")
+	if err != nil {
+		return err
+	}
+	d.buf[d.slot(i)] = value
+	return nil
+}
+
+// Copies the elements out from front to back
+func (d *Deque) Elements() []any {
+	elements := make([]any, d.size)
+	for i := range elements {
+		elements[i] = d.buf[d.slot(i)]
+	}
+	return elements
+}
+
+func (d *Deque) String() string {
+	if printing[d] {
+		return "deque[...]"
+	}
+	printing[d] = true
+	defer delete(printing, d)
+
+	parts := make([]string, d.size)
+	for i := range parts {
+		parts[i] = repr(d.buf[d.slot(i)])
+	}
+	return "deque[" + strings.Join(parts, ", ") + "]"
+}
+
+func init() {
+	register("deque", 0, func(it *Interpreter, args []any) (any, error) {
+		return NewDeque(), nil
+	})
+	register("pushFront", 2, func(it *Interpreter, args []any) (any, error) {
+		d, err := dequeArgument("pushFront", args[0])
+		if err != nil {
+			return nil, err
+		}
+		d.PushFront(args[1])
+		return nil, nil
+	})
+	register("pushBack", 2, func(it *Interpreter, args []any) (any, error) {
+		d, err := dequeArgument("pushBack", args[0])
+		if err != nil {
+			return nil, err
+		}
+		d.PushBack(args[1])
+		return nil, nil
+	})
+	register("popFront", 1, func(it *Interpreter, args []any) (any, error) {
+		d, err := dequeArgument("popFront", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return d.PopFront()
+	})
+	register("popBack", 1, func(it *Interpreter, args []any) (any, error) {
+		d, err := dequeArgument("popBack", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return d.PopBack()
+	})
+	register("peekFront", 1, func(it *Interpreter, args []any) (any, error) {
+		d, err := dequeArgument("peekFront", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return d.PeekFront()
+	})
+	register("peekBack", 1, func(it *Interpreter, args []any) (any, error) {
+		d, err := dequeArgument("peekBack", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return d.PeekBack()
+	})
+}
+
+func dequeArgument(name string, arg any) (*Deque, error) {
+	d, ok := arg.(*Deque)
+	if !ok {
+		return nil, fmt.Errorf("%s expects a deque but got %s", name, Stringify(arg))
+	}
+	return d, nil
+}
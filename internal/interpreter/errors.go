@@ -0,0 +1,99 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maxcelant/kiwi/internal/diagnostics"
+	"github.com/maxcelant/kiwi/internal/lexer"
+)
+
+// How deep kiwi calls can nest before the program is stopped with a stack overflow
+const maxCallDepth = 10000
+
+// Only the innermost frames are printed, a deep recursion would otherwise
+// bury the message under thousands of identical lines
+const maxPrintedFrames = 10
+
+// A kiwi function or class that was being called when an error happened, and
+// the ')' of the call that entered it
+type Frame struct {
+	Function string
+	Call     lexer.Token
+}
+
+// An error raised while running a kiwi program. Token is what was being
+// evaluated when it failed, and Stack holds the calls that led there, starting
+// with the innermost one.
+type RuntimeError struct {
+	Token   lexer.Token
+	Message string
+	File    string
+	Stack   []Frame
+}
+
+// Prints as `file:line:col: message`, followed by one line per frame
+func (e *RuntimeError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.location(e.Token))
+	b.WriteString(e.Message)
+	frames, hidden := e.printedFrames()
+	for _, frame := range frames {
+		fmt.Fprintf(&b, "\n\tin %s, called at %s", frame.Function, strings.TrimSuffix(e.location(frame.Call), ": "))
+	}
+	if hidden > 0 {
+		fmt.Fprintf(&b, "\n\t... %d more calls", hidden)
+	}
+	return b.String()
+}
+
+func (e *RuntimeError) location(token lexer.Token) string {
+	if e.File == "" {
+		return fmt.Sprintf("%d:%d: ", token.Line, token.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d: ", e.File, token.Line, token.Column)
+}
+
+// Runtime errors can be rendered like any other diagnostic, with the frames
+// listed as notes beneath the snippet
+func (e *RuntimeError) Unwrap() error {
+	frames, hidden := e.printedFrames()
+	notes := make([]string, len(frames))
+	for i, frame := range frames {
+		notes[i] = fmt.Sprintf("in %s, called at line %d, column %d", frame.Function, frame.Call.Line, frame.Call.Column)
+	}
+	if hidden > 0 {
+		notes = append(notes, fmt.Sprintf("... %d more calls", hidden))
+	}
+	return diagnostics.New(diagnostics.Error, e.Token.Span(), e.Message, notes...)
+}
+
+func (e *RuntimeError) printedFrames() ([]Frame, int) {
+	if len(e.Stack) <= maxPrintedFrames {
+		return e.Stack, 0
+	}
+	return e.Stack[:maxPrintedFrames], len(e.Stack) - maxPrintedFrames
+}
+
+func (it *Interpreter) error(token lexer.Token, message string) *RuntimeError {
+	stack := make([]Frame, len(it.frames))
+	for i, frame := range it.frames {
+		stack[len(it.frames)-1-i] = frame
+	}
+	return &RuntimeError{Token: token, Message: message, File: it.file, Stack: stack}
+}
+
+// Points an error at the token it came from, unless it is already a runtime
+// error pointing somewhere deeper, such as a line inside the function called
+func (it *Interpreter) locate(token lexer.Token, err error) error {
+	var runtimeErr *RuntimeError
+	if err == nil || errors.As(err, &runtimeErr) {
+		return err
+	}
+	var d *diagnostics.Diagnostic
+	if errors.As(err, &d) {
+		return it.error(token, d.Message)
+	}
+	return it.error(token, err.Error())
+}
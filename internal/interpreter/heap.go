@@ -0,0 +1,264 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reports whether a should come out of the heap before b
+type lessFunc func(a any, b any) (bool, error)
+
+// A binary heap stored in a slice, where the children of items[i] live at
+// 2i+1 and 2i+2. By default it is a min-heap using the same ordering as
+// compareValues, but the order can be flipped or replaced with a kiwi function.
+type Heap struct {
+	items   []any
+	less    lessFunc
+	ordered bool // Whether less is compareValues, so every item has to be orderable
+}
+
+func NewHeap(less lessFunc) *Heap {
+	return &Heap{items: []any{}, less: less}
+}
+
+// A heap that uses compareValues, either smallest or largest first. With only
+// one item nothing gets compared, so items are checked as they go in rather
+// than letting a nil through to break every push after it.
+func newOrderedHeap(less lessFunc) *Heap {
+	h := NewHeap(less)
+	h.ordered = true
+	return h
+}
+
+func minOrder(a any, b any) (bool, error) {
+	c, err := compareValues(a, b)
+	return c < 0, err
+}
+
+func maxOrder(a any, b any) (bool, error) {
+	c, err := compareValues(a, b)
+	return c > 0, err
+}
+
+// Wraps a kiwi function so the heap can call back into the interpreter whenever
+// it compares two items. The function is declared and then passed by name, as in
+// `fn bySecond(a, b) { return a[1] < b[1]; } heapWith(bySecond);`
+func userOrder(it *Interpreter, fn Callable) lessFunc {
+	return func(a any, b any) (bool, error) {
+		result, err := fn.Call(it, []any{a, b})
+		if err != nil {
+			return false, err
+		}
+		return IsTruthy(result), nil
+	}
+}
+
+func (h *Heap) Len() int {
+	return len(h.items)
+}
+
+// A comparison can fail partway through, such as when a string is pushed onto
+// a heap of numbers. Every operation records the swaps it makes so it can undo
+// them and leave the heap exactly as it was before returning the error.
+type swapLog [][2]int
+
+func (h *Heap) Push(value any) error {
+	if err := h.check(value); err != nil {
+		return err
+	}
+	h.items = append(h.items, value)
+	var log swapLog
+	if err := h.up(len(h.items)-1, &log); err != nil {
+		h.undo(log)
+		h.items[len(h.items)-1] = nil
+		h.items = h.items[:len(h.items)-1]
+		return err
+	}
+	return nil
+}
+
+func (h *Heap) Pop() (any, error) {
+	if len(h.items) == 0 {
+		return nil, fmt.Errorf("cannot pop from an empty heap")
+	}
+	top := h.items[0]
+	last := len(h.items) - 1
+	h.items[0] = h.items[last]
+	h.items[last] = nil // Don't hold on to values that have been popped
+	h.items = h.items[:last]
+	var log swapLog
+	if err := h.down(0, &log); err != nil {
+		h.undo(log)
+		// The item that was moved up to the root goes back to the end
+		h.items = append(h.items, h.items[0])
+		h.items[0] = top
+		return nil, err
+	}
+	return top, nil
+}
+
+func (h *Heap) Peek() (any, error) {
+	if len(h.items) == 0 {
+		return nil, fmt.Errorf("cannot peek at an empty heap")
+	}
+	return h.items[0], nil
+}
+
+// Adds every value at once and then restores the heap from the bottom up,
+// which is O(n) rather than the O(n log n) of pushing them one at a time
+func (h *Heap) PushAll(values []any) error {
+	for _, value := range values {
+		if err := h.check(value); err != nil {
+			return err
+		}
+	}
+	size := len(h.items)
+	h.items = append(h.items, values...)
+	var log swapLog
+	for i := len(h.items)/2 - 1; i >= 0; i-- {
+		if err := h.down(i, &log); err != nil {
+			h.undo(log)
+			clear(h.items[size:])
+			h.items = h.items[:size]
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Heap) check(value any) error {
+	if !h.ordered {
+		return nil
+	}
+	return orderable(value)
+}
+
+func (h *Heap) up(i int, log *swapLog) error {
+	for i > 0 {
+		parent := (i - 1) / 2
+		less, err := h.less(h.items[i], h.items[parent])
+		if err != nil {
+			return err
+		}
+		if !less {
+			return nil
+		}
+		h.swap(i, parent, log)
+		i = parent
+	}
+	return nil
+}
+
+func (h *Heap) down(i int, log *swapLog) error {
+	for {
+		smallest := i
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child >= len(h.items) {
+				continue
+			}
+			less, err := h.less(h.items[child], h.items[smallest])
+			if err != nil {
+				return err
+			}
+			if less {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			return nil
+		}
+		h.swap(i, smallest, log)
+		i = smallest
+	}
+}
+
+func (h *Heap) swap(i int, j int, log *swapLog) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	*log = append(*log, [2]int{i, j})
+}
+
+// Reverses the swaps, newest first
+func (h *Heap) undo(log swapLog) {
+	for k := len(log) - 1; k >= 0; k-- {
+		i, j := log[k][0], log[k][1]
+		h.items[i], h.items[j] = h.items[j], h.items[i]
+	}
+}
+
+// Prints the items in the order they are stored, so only the first is guaranteed
+// to be in its final position
+func (h *Heap) String() string {
+	if printing[h] {
+		return "heap[...]"
+	}
+	printing[h] = true
+	defer delete(printing, h)
+
+	parts := make([]string, len(h.items))
+	for i, item := range h.items {
+		parts[i] = repr(item)
+	}
+	return "heap[" + strings.Join(parts, ", ") + "]"
+}
+
+func init() {
+	register("heap", 0, func(it *Interpreter, args []any) (any, error) {
+		return newOrderedHeap(minOrder), nil
+	})
+	register("maxHeap", 0, func(it *Interpreter, args []any) (any, error) {
+		return newOrderedHeap(maxOrder), nil
+	})
+	register("heapWith", 1, func(it *Interpreter, args []any) (any, error) {
+		fn, ok := args[0].(Callable)
+		if !ok {
+			return nil, fmt.Errorf("heapWith expects a function but got %s", Stringify(args[0]))
+		}
+		if fn.Arity() != 2 {
+			return nil, fmt.Errorf("heap comparator must take 2 arguments but takes %d", fn.Arity())
+		}
+		return NewHeap(userOrder(it, fn)), nil
+	})
+	register("heapify", 2, func(it *Interpreter, args []any) (any, error) {
+		h, err := heapArgument("heapify", args[0])
+		if err != nil {
+			return nil, err
+		}
+		list, ok := args[1].(*List)
+		if !ok {
+			return nil, fmt.Errorf("heapify expects a list but got %s", Stringify(args[1]))
+		}
+		if err := h.PushAll(list.elements); err != nil {
+			return nil, err
+		}
+		return h, nil
+	})
+	register("heapPush", 2, func(it *Interpreter, args []any) (any, error) {
+		h, err := heapArgument("heapPush", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return nil, h.Push(args[1])
+	})
+	register("heapPop", 1, func(it *Interpreter, args []any) (any, error) {
+		h, err := heapArgument("heapPop", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return h.Pop()
+	})
+	register("heapPeek", 1, func(it *Interpreter, args []any) (any, error) {
+		h, err := heapArgument("heapPeek", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return h.Peek()
+	})
+}
+
+func heapArgument(name string, arg any) (*Heap, error) {
+	h, ok := arg.(*Heap)
+	if !ok {
+		return nil, fmt.Errorf("%s expects a heap but got %s", name, Stringify(arg))
+	}
+	return h, nil
+}
@@ -1,6 +1,12 @@
 package interpreter
 
-import "fmt"
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
 
 type ComparatorFunc func(a any, b any) bool
 
@@ -35,6 +41,29 @@ func WithInt() ComparatorFunc {
 	}
 }
 
+// Accepts any mix of ints and floats, since those get promoted to a common type
+func WithNumber() ComparatorFunc {
+	return func(a any, b any) bool {
+		_, ok := toFloat(a)
+		if !ok {
+			return false
+		}
+		_, ok = toFloat(b)
+		return ok
+	}
+}
+
+func WithList() ComparatorFunc {
+	return func(a any, b any) bool {
+		a, ok := a.(*List)
+		if !ok {
+			return false
+		}
+		b, ok = b.(*List)
+		return ok
+	}
+}
+
 func WithBool() ComparatorFunc {
 	return func(a any, b any) bool {
 		a, ok := a.(bool)
@@ -54,7 +83,7 @@ func Stringify(obj any) string {
 		return fmt.Sprintf("%d", v)
 	}
 	if v, ok := obj.(float64); ok {
-		return fmt.Sprintf("%f", v)
+		return formatFloat(v)
 	}
 	if v, ok := obj.(string); ok {
 		return v
@@ -62,9 +91,21 @@ func Stringify(obj any) string {
 	if v, ok := obj.(bool); ok {
 		return fmt.Sprintf("%t", v)
 	}
+	if v, ok := obj.(fmt.Stringer); ok {
+		return v.String()
+	}
 	return fmt.Sprintf("unsupported type: %T", obj)
 }
 
+// Like Stringify, but strings are quoted. Used for values nested inside of lists
+// and maps, so that `["1", 1]` doesn't print as `[1, 1]`
+func repr(obj any) string {
+	if s, ok := obj.(string); ok {
+		return strconv.Quote(s)
+	}
+	return Stringify(obj)
+}
+
 func IsTruthy(v any) bool {
 	if v == nil {
 		return false
@@ -75,11 +116,229 @@ func IsTruthy(v any) bool {
 	return true
 }
 
-func isNumber(v any) (int, bool) {
-	switch v.(type) {
+// Prints the shortest representation that round trips, so 2.50 prints as 2.5.
+// Very large or very small values switch to exponent form instead of a wall of zeros.
+func formatFloat(v float64) string {
+	abs := math.Abs(v)
+	if abs != 0 && (abs >= 1e21 || abs < 1e-6) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// Numbers stay ints as long as both operands are ints. As soon as either side
+// is a float, both get promoted to float64 so nothing is silently truncated.
+func arithmetic(left any, right any, intOp func(int, int) int, floatOp func(float64, float64) float64) any {
+	l, lok := left.(int)
+	r, rok := right.(int)
+	if lok && rok {
+		return intOp(l, r)
+	}
+	lf, _ := toFloat(left)
+	rf, _ := toFloat(right)
+	return floatOp(lf, rf)
+}
+
+// Compares two numbers using the same promotion rules as arithmetic, returning
+// a negative number, zero or a positive number like strings.Compare
+func compareNumbers(left any, right any) int {
+	l, lok := left.(int)
+	r, rok := right.(int)
+	if lok && rok {
+		return cmp.Compare(l, r)
+	}
+	lf, _ := toFloat(left)
+	rf, _ := toFloat(right)
+	return cmp.Compare(lf, rf)
+}
+
+// A container can end up holding itself, as in `xs[0] = xs`. These track the
+// containers partway through being printed, and the pairs of lists partway
+// through being compared, so that reaching one again is treated as a cycle
+// rather than recursing until the Go stack runs out. They are shared by every
+// Interpreter, so two interpreters printing or comparing on different
+// goroutines at the same time will race; that is a known limitation.
+var (
+	printing  = map[any]bool{}
+	comparing = map[[2]*List]bool{}
+)
+
+// Orders two values for the comparison operators and the heap and tree
+// builtins, so they all agree with each other. Numbers compare by value,
+// strings compare byte-wise and lists compare element by element, with a
+// shorter list coming first when it is a prefix of the longer one.
+func compareValues(left any, right any) (int, error) {
+	if Compare(left, right, WithNumber()) {
+		return compareNumbers(left, right), nil
+	}
+	if Compare(left, right, WithString()) {
+		return strings.Compare(left.(string), right.(string)), nil
+	}
+	if Compare(left, right, WithList()) {
+		pair := [2]*List{left.(*List), right.(*List)}
+		if comparing[pair] {
+			return 0, nil
+		}
+		comparing[pair] = true
+		defer delete(comparing, pair)
+
+		l, r := pair[0].elements, pair[1].elements
+		for i := 0; i < len(l) && i < len(r); i++ {
+			c, err := compareValues(l[i], r[i])
+			if err != nil || c != 0 {
+				return c, err
+			}
+		}
+		return cmp.Compare(len(l), len(r)), nil
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", typeName(left), typeName(right))
+}
+
+// Checks that a value can go into something kept in compareValues order, such
+// as a heap or tree. Comparing the value with itself catches lists holding
+// things that can't be ordered too.
+func orderable(v any) error {
+	if _, err := compareValues(v, v); err != nil {
+		return fmt.Errorf("only numbers, strings and lists of them can be ordered, got %s", typeName(v))
+	}
+	return nil
+}
+
+func typeName(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
 	case int, float64:
-		return v.(int), true
+		return "number"
+	case string:
+		return "string"
+	case *List:
+		return "list"
+	case *Map:
+		return "map"
+	case *Deque:
+		return "deque"
+	case *Heap:
+		return "heap"
+	case *Tree:
+		if v.isSet {
+			return "tree set"
+		}
+		return "tree map"
+	case *Class:
+		return "class"
+	case *Instance:
+		return "instance"
+	case Callable:
+		return "function"
 	default:
-		return 0.0, false
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// Checks an index into something of the given length, such as a list or a
+// string. Negative indexes count back from the end, so `xs[-1]` is the last element.
+func position(index any, length int, kind string) (int, error) {
+	i, ok := index.(int)
+	if !ok {
+		return 0, fmt.Errorf("%s index must be an integer", kind)
+	}
+	p := i
+	if p < 0 {
+		p += length
+	}
+	if p < 0 || p >= length {
+		return 0, fmt.Errorf("index %d out of range for %s of length %d", i, kind, length)
+	}
+	return p, nil
+}
+
+// Turns the bounds of a slice into a half-open range [start, end). Missing bounds
+// default to the whole sequence, negative bounds count back from the end, and
+// anything past either end is clamped rather than an error, so `s[:100]` is fine.
+func sliceBounds(start any, end any, length int) (int, int, error) {
+	bound := func(v any, fallback int) (int, error) {
+		if v == nil {
+			return fallback, nil
+		}
+		i, ok := v.(int)
+		if !ok {
+			return 0, fmt.Errorf("slice bounds must be integers")
+		}
+		if i < 0 {
+			i += length
+		}
+		return min(max(i, 0), length), nil
+	}
+	s, err := bound(start, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	e, err := bound(end, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s, max(s, e), nil
+}
+
+// Floor division and modulo round towards negative infinity, so that
+// `a == (a ~/ b) * b + a % b` always holds and `-1 % 5` is 4 rather than -1
+func floorDiv(a int, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q -= 1
+	}
+	return q
+}
+
+func floorMod(a int, b int) int {
+	return a - floorDiv(a, b)*b
+}
+
+func floatMod(a float64, b float64) float64 {
+	return a - math.Floor(a/b)*b
+}
+
+// Exponentiation by squaring, only used for non-negative integer exponents
+func intPow(base int, exp int) int {
+	result := 1
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+
+func isZero(v any) bool {
+	f, ok := toFloat(v)
+	return ok && f == 0
+}
+
+// Numbers are equal by value regardless of whether they are ints or floats,
+// and lists are equal when their elements are. Everything else, such as instances, maps and
+// nil, is only equal to itself.
+func isEqual(left any, right any) bool {
+	if Compare(left, right, WithNumber()) {
+		return compareNumbers(left, right) == 0
+	}
+	if Compare(left, right, WithList()) {
+		return left.(*List).Equals(right.(*List))
 	}
+	return left == right
 }
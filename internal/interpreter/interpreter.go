@@ -1,34 +1,68 @@
 package interpreter
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
 
 	"github.com/maxcelant/kiwi/internal/env"
 	"github.com/maxcelant/kiwi/internal/expr"
 	"github.com/maxcelant/kiwi/internal/lexer"
+	"github.com/maxcelant/kiwi/internal/resolver"
 	"github.com/maxcelant/kiwi/internal/stmt"
 )
 
 type Interpreter struct {
 	stmts       []stmt.Stmt
+	globals     *env.Environment
 	environment *env.Environment
+	locals      resolver.Locals
+	frames      []Frame // The kiwi functions currently being called, outermost first
+	file        string
+	out         io.Writer // Where print statements write to
 }
 
 func New(stmts []stmt.Stmt, environment *env.Environment) *Interpreter {
+	defineNatives(environment)
 	return &Interpreter{
 		stmts:       stmts,
+		globals:     environment,
 		environment: environment,
+		locals:      resolver.Locals{},
+		out:         os.Stdout,
 	}
 }
 
-func (it *Interpreter) Interpret() {
+// Records the scope depths found by the resolver. These are merged rather than
+// replaced so functions declared by earlier programs keep their bindings.
+func (it *Interpreter) Resolve(locals resolver.Locals) {
+	for ex, depth := range locals {
+		it.locals[ex] = depth
+	}
+}
+
+// Names the file being run, which runtime errors are reported against
+func (it *Interpreter) SetFile(name string) {
+	it.file = name
+}
+
+// Sends the output of print statements somewhere other than stdout
+func (it *Interpreter) SetOutput(out io.Writer) {
+	it.out = out
+}
+
+// Runs the program, stopping at the first runtime error
+func (it *Interpreter) Interpret() error {
 	for _, st := range it.stmts {
 		err := it.Execute(st)
 		if err != nil {
-			fmt.Println(err)
-			return
+			return err
 		}
 	}
+	return nil
 }
 
 func (it *Interpreter) Execute(st stmt.Stmt) error {
@@ -40,11 +74,86 @@ func (it *Interpreter) Execute(st stmt.Stmt) error {
 }
 
 func (it *Interpreter) Evaluate(ex expr.Expr) (any, error) {
-	v, err := ex.Accept(it)
+	return ex.Accept(it)
+}
+
+func (it *Interpreter) VisitIfStatement(st stmt.Stmt) error {
+	ifStmt, ok := st.(stmt.If)
+	if !ok {
+		return fmt.Errorf("not an if statement")
+	}
+
+	condition, err := it.Evaluate(ifStmt.Condition)
 	if err != nil {
-		return nil, fmt.Errorf("failed to evaluate expression: %w", err)
+		return err
+	}
+
+	if IsTruthy(condition) {
+		return it.Execute(ifStmt.ThenBranch)
+	}
+	if ifStmt.ElseBranch != nil {
+		return it.Execute(ifStmt.ElseBranch)
+	}
+	return nil
+}
+
+func (it *Interpreter) VisitWhileStatement(st stmt.Stmt) error {
+	whileStmt, ok := st.(stmt.While)
+	if !ok {
+		return fmt.Errorf("not a while statement")
+	}
+
+	for {
+		condition, err := it.Evaluate(whileStmt.Condition)
+		if err != nil {
+			return err
+		}
+		if !IsTruthy(condition) {
+			return nil
+		}
+		err = it.Execute(whileStmt.Body)
+		if errors.Is(err, errBreak) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, errContinue) {
+			return err
+		}
+		if whileStmt.Increment != nil {
+			if _, err := it.Evaluate(whileStmt.Increment); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (it *Interpreter) VisitBreakStatement(st stmt.Stmt) error {
+	if _, ok := st.(stmt.Break); !ok {
+		return fmt.Errorf("not a break statement")
+	}
+	return errBreak
+}
+
+func (it *Interpreter) VisitContinueStatement(st stmt.Stmt) error {
+	if _, ok := st.(stmt.Continue); !ok {
+		return fmt.Errorf("not a continue statement")
 	}
-	return v, nil
+	return errContinue
+}
+
+func (it *Interpreter) VisitReturnStatement(st stmt.Stmt) error {
+	var err error
+	var v any
+	returnStmt, ok := st.(stmt.Return)
+	if !ok {
+		return fmt.Errorf("not a return statement")
+	}
+	if returnStmt.Value != nil {
+		v, err = it.Evaluate(returnStmt.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return &returnSignal{value: v}
 }
 
 func (it *Interpreter) VisitBlockStatement(st stmt.Stmt) error {
@@ -52,19 +161,68 @@ func (it *Interpreter) VisitBlockStatement(st stmt.Stmt) error {
 	if !ok {
 		return fmt.Errorf("not an block statement")
 	}
+	return it.executeBlock(block.Statements, env.New(it.environment))
+}
 
+// Runs the statements inside of the given environment, which is how both blocks
+// and function bodies get their own scope
+func (it *Interpreter) executeBlock(stmts []stmt.Stmt, environment *env.Environment) error {
 	parent := it.environment
-	child := env.New(parent)
-	it.environment = child
+	it.environment = environment
+	// Restore the parent scope even when a break, continue, return or error
+	// unwinds out of the block early
+	defer func() { it.environment = parent }()
 
-	for _, st := range block.Statements {
+	for _, st := range stmts {
 		err := it.Execute(st)
 		if err != nil {
 			return err
 		}
 	}
 
-	it.environment = parent
+	return nil
+}
+
+func (it *Interpreter) VisitClassDeclaration(st stmt.Stmt) error {
+	classStmt, ok := st.(stmt.Class)
+	if !ok {
+		return fmt.Errorf("not a class declaration")
+	}
+
+	var superclass *Class
+	if classStmt.Superclass != nil {
+		v, err := it.Evaluate(classStmt.Superclass)
+		if err != nil {
+			return err
+		}
+		superclass, ok = v.(*Class)
+		if !ok {
+			return it.error(classStmt.Superclass.Name, "superclass must be a class")
+		}
+	}
+
+	// The methods of a subclass close over an environment that holds 'super',
+	// matching the extra scope the resolver created for them
+	closure := it.environment
+	if superclass != nil {
+		closure = env.New(it.environment)
+		closure.Define("super", superclass)
+	}
+
+	methods := make(map[string]*Function)
+	for _, method := range classStmt.Methods {
+		methods[method.Name.Lexeme] = NewFunction(method, closure, method.Name.Lexeme == "init")
+	}
+	it.environment.Define(classStmt.Name.Lexeme, NewClass(classStmt.Name.Lexeme, superclass, methods))
+	return nil
+}
+
+func (it *Interpreter) VisitFunctionDeclaration(st stmt.Stmt) error {
+	fnStmt, ok := st.(stmt.Function)
+	if !ok {
+		return fmt.Errorf("not a function declaration")
+	}
+	it.environment.Define(fnStmt.Name.Lexeme, NewFunction(fnStmt, it.environment, false))
 	return nil
 }
 
@@ -109,12 +267,12 @@ func (it *Interpreter) VisitPrintStatement(st stmt.Stmt) error {
 	if err != nil {
 		return err
 	}
-	fmt.Println(Stringify(v))
+	fmt.Fprintln(it.out, Stringify(v))
 	return nil
 }
 
 func (it *Interpreter) VisitAssign(ex expr.Expr) (any, error) {
-	assign, ok := ex.(expr.Assign)
+	assign, ok := ex.(*expr.Assign)
 	if !ok {
 		return nil, fmt.Errorf("not an assign expression")
 	}
@@ -124,9 +282,14 @@ func (it *Interpreter) VisitAssign(ex expr.Expr) (any, error) {
 		return nil, err
 	}
 
-	err = it.environment.Assign(assign.Name, value)
+	if depth, ok := it.locals[assign]; ok {
+		it.environment.AssignAt(depth, assign.Name, value)
+		return nil, nil
+	}
+
+	err = it.globals.Assign(assign.Name, value)
 	if err != nil {
-		return nil, err
+		return nil, it.locate(assign.Name, err)
 	}
 
 	return nil, nil
@@ -174,85 +337,144 @@ func (it *Interpreter) VisitBinary(ex expr.Expr) (any, error) {
 		return nil, err
 	}
 
+	// Any two values can be compared for equality, values of different types are never equal
 	if binary.Operator.Type == lexer.EQUAL_EQUAL {
-		if ok := Compare(left, right, WithInt(), WithBool(), WithString()); !ok {
-			return nil, fmt.Errorf("operands must be a number or boolean for equality operation")
-		}
-		return left == right, nil
+		return isEqual(left, right), nil
 	}
 
 	if binary.Operator.Type == lexer.BANG_EQ {
-		if ok := Compare(left, right, WithInt(), WithBool(), WithString()); !ok {
-			return nil, fmt.Errorf("operands must be a number or boolean for inequality operation")
-		}
-		return left != right, nil
+		return !isEqual(left, right), nil
 	}
 
 	if binary.Operator.Type == lexer.GREATER {
-		if ok := Compare(left, right, WithInt()); !ok {
-			return nil, fmt.Errorf("operands must be a number for greater than operation")
+		if ok := Compare(left, right, WithNumber(), WithString(), WithList()); !ok {
+			return nil, it.error(binary.Operator, "operands must be two numbers, strings or lists for greater than operation")
+		}
+		c, err := compareValues(left, right)
+		if err != nil {
+			return nil, it.error(binary.Operator, err.Error())
 		}
-		return left.(int) > right.(int), nil
+		return c > 0, nil
 	}
 
 	if binary.Operator.Type == lexer.GREATER_EQ {
-		if ok := Compare(left, right, WithInt()); !ok {
-			return nil, fmt.Errorf("operands must be a number for greater than or equal operation")
+		if ok := Compare(left, right, WithNumber(), WithString(), WithList()); !ok {
+			return nil, it.error(binary.Operator, "operands must be two numbers, strings or lists for greater than or equal operation")
 		}
-		return left.(int) >= right.(int), nil
+		c, err := compareValues(left, right)
+		if err != nil {
+			return nil, it.error(binary.Operator, err.Error())
+		}
+		return c >= 0, nil
 	}
 
 	if binary.Operator.Type == lexer.LESS {
-		if ok := Compare(left, right, WithInt()); !ok {
-			return nil, fmt.Errorf("operands must be a number for less than operation")
+		if ok := Compare(left, right, WithNumber(), WithString(), WithList()); !ok {
+			return nil, it.error(binary.Operator, "operands must be two numbers, strings or lists for less than operation")
+		}
+		c, err := compareValues(left, right)
+		if err != nil {
+			return nil, it.error(binary.Operator, err.Error())
 		}
-		return left.(int) < right.(int), nil
+		return c < 0, nil
 	}
 
 	if binary.Operator.Type == lexer.LESS_EQ {
-		if ok := Compare(left, right, WithInt()); !ok {
-			return nil, fmt.Errorf("operands must be a number for less than or equal operation")
+		if ok := Compare(left, right, WithNumber(), WithString(), WithList()); !ok {
+			return nil, it.error(binary.Operator, "operands must be two numbers, strings or lists for less than or equal operation")
 		}
-		return left.(int) <= right.(int), nil
+		c, err := compareValues(left, right)
+		if err != nil {
+			return nil, it.error(binary.Operator, err.Error())
+		}
+		return c <= 0, nil
 	}
 
 	if binary.Operator.Type == lexer.PLUS {
-		if ok := Compare(left, right, WithInt(), WithString()); !ok {
-			return nil, fmt.Errorf("operands must both be a numbers or strings for add operation")
+		if ok := Compare(left, right, WithNumber(), WithString()); !ok {
+			return nil, it.error(binary.Operator, "operands must both be a numbers or strings for add operation")
 		}
 
-		switch left := left.(type) {
-		case int:
-			return left + right.(int), nil
-		case string:
+		if left, ok := left.(string); ok {
 			return left + right.(string), nil
-		default:
-			return nil, fmt.Errorf("operands must be either both numbers or both strings for add operation")
 		}
+		return arithmetic(left, right,
+			func(a, b int) int { return a + b },
+			func(a, b float64) float64 { return a + b },
+		), nil
 	}
 
 	if binary.Operator.Type == lexer.MINUS {
-		if ok := Compare(left, right, WithInt()); !ok {
-			return nil, fmt.Errorf("operands must be a number for subtract operation")
+		if ok := Compare(left, right, WithNumber()); !ok {
+			return nil, it.error(binary.Operator, "operands must be a number for subtract operation")
 		}
-		return left.(int) - right.(int), nil
+		return arithmetic(left, right,
+			func(a, b int) int { return a - b },
+			func(a, b float64) float64 { return a - b },
+		), nil
 	}
 
 	if binary.Operator.Type == lexer.SLASH {
-		if ok := Compare(left, right, WithInt()); !ok {
-			return nil, fmt.Errorf("operands must be a number for division operation")
+		if ok := Compare(left, right, WithNumber()); !ok {
+			return nil, it.error(binary.Operator, "operands must be a number for division operation")
 		}
-		if right.(int) == 0 {
-			return nil, fmt.Errorf("cannot perform division by zero")
+		if isZero(right) {
+			return nil, it.error(binary.Operator, "cannot perform division by zero")
 		}
-		return left.(int) / right.(int), nil
+		// Dividing two ints keeps truncating towards zero, like it always has
+		return arithmetic(left, right,
+			func(a, b int) int { return a / b },
+			func(a, b float64) float64 { return a / b },
+		), nil
 	}
 
 	if binary.Operator.Type == lexer.STAR {
-		if ok := Compare(left, right, WithInt()); !ok {
-			return nil, fmt.Errorf("operands must be a number for multiplication operation")
+		if ok := Compare(left, right, WithNumber()); !ok {
+			return nil, it.error(binary.Operator, "operands must be a number for multiplication operation")
 		}
-		return left.(int) * right.(int), nil
+		return arithmetic(left, right,
+			func(a, b int) int { return a * b },
+			func(a, b float64) float64 { return a * b },
+		), nil
+	}
+
+	if binary.Operator.Type == lexer.TILDE_SLASH {
+		if ok := Compare(left, right, WithNumber()); !ok {
+			return nil, it.error(binary.Operator, "operands must be a number for floor division operation")
+		}
+		if isZero(right) {
+			return nil, it.error(binary.Operator, "cannot perform floor division by zero")
+		}
+		return arithmetic(left, right,
+			floorDiv,
+			func(a, b float64) float64 { return math.Floor(a / b) },
+		), nil
+	}
+
+	if binary.Operator.Type == lexer.PERCENT {
+		if ok := Compare(left, right, WithNumber()); !ok {
+			return nil, it.error(binary.Operator, "operands must be a number for modulo operation")
+		}
+		if isZero(right) {
+			return nil, it.error(binary.Operator, "cannot perform modulo by zero")
+		}
+		return arithmetic(left, right, floorMod, floatMod), nil
+	}
+
+	if binary.Operator.Type == lexer.STAR_STAR {
+		if ok := Compare(left, right, WithNumber()); !ok {
+			return nil, it.error(binary.Operator, "operands must be a number for exponent operation")
+		}
+		// Zero to a negative power is 1 / 0 in disguise
+		if isZero(left) && compareNumbers(right, 0) < 0 {
+			return nil, it.error(binary.Operator, "cannot raise zero to a negative power")
+		}
+		// A negative integer exponent can't produce an int, so it goes through floats
+		if exp, ok := right.(int); ok && exp < 0 {
+			base, _ := toFloat(left)
+			return math.Pow(base, float64(exp)), nil
+		}
+		return arithmetic(left, right, intPow, math.Pow), nil
 	}
 
 	return "", nil
@@ -274,16 +496,259 @@ func (it *Interpreter) VisitUnary(ex expr.Expr) (any, error) {
 	}
 
 	if unary.Operator.Type == lexer.MINUS {
-		num, ok := isNumber(right)
-		if !ok {
-			return nil, fmt.Errorf("operand must be a number")
+		switch num := right.(type) {
+		case int:
+			return -num, nil
+		case float64:
+			return -num, nil
+		default:
+			return nil, it.error(unary.Operator, "operand must be a number")
 		}
-		return -num, nil
 	}
 
 	return "", nil
 }
 
+func (it *Interpreter) VisitCall(ex expr.Expr) (any, error) {
+	call, ok := ex.(expr.Call)
+	if !ok {
+		return nil, fmt.Errorf("not a call expression")
+	}
+
+	callee, err := it.Evaluate(call.Callee)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []any{}
+	for _, arg := range call.Arguments {
+		v, err := it.Evaluate(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	fn, ok := callee.(Callable)
+	if !ok {
+		return nil, it.error(call.Paren, "can only call functions and classes")
+	}
+	if len(args) != fn.Arity() {
+		return nil, it.error(call.Paren, fmt.Sprintf("expected %d arguments but got %d", fn.Arity(), len(args)))
+	}
+
+	// Only kiwi functions and classes get a frame, a native that fails is
+	// reported at the call itself
+	var name string
+	switch fn := fn.(type) {
+	case *Function:
+		name = fn.declaration.Name.Lexeme
+	case *Class:
+		name = fn.name
+	default:
+		value, err := fn.Call(it, args)
+		return value, it.locate(call.Paren, err)
+	}
+	// Runaway recursion has to stop while there's still room on the Go stack,
+	// otherwise the whole process dies rather than reporting an error
+	if len(it.frames) >= maxCallDepth {
+		return nil, it.error(call.Paren, fmt.Sprintf("stack overflow, more than %d nested calls", maxCallDepth))
+	}
+	it.frames = append(it.frames, Frame{Function: name, Call: call.Paren})
+	value, err := fn.Call(it, args)
+	it.frames = it.frames[:len(it.frames)-1]
+	return value, it.locate(call.Paren, err)
+}
+
+func (it *Interpreter) VisitGet(ex expr.Expr) (any, error) {
+	get, ok := ex.(expr.Get)
+	if !ok {
+		return nil, fmt.Errorf("not a get expression")
+	}
+
+	object, err := it.Evaluate(get.Object)
+	if err != nil {
+		return nil, err
+	}
+	instance, ok := object.(*Instance)
+	if !ok {
+		return nil, it.error(get.Name, "only instances have properties")
+	}
+	value, err := instance.Get(get.Name)
+	return value, it.locate(get.Name, err)
+}
+
+func (it *Interpreter) VisitSet(ex expr.Expr) (any, error) {
+	set, ok := ex.(expr.Set)
+	if !ok {
+		return nil, fmt.Errorf("not a set expression")
+	}
+
+	object, err := it.Evaluate(set.Object)
+	if err != nil {
+		return nil, err
+	}
+	instance, ok := object.(*Instance)
+	if !ok {
+		return nil, it.error(set.Name, "only instances have fields")
+	}
+
+	value, err := it.Evaluate(set.Value)
+	if err != nil {
+		return nil, err
+	}
+	instance.Set(set.Name, value)
+	return value, nil
+}
+
+func (it *Interpreter) VisitIndex(ex expr.Expr) (any, error) {
+	index, ok := ex.(expr.Index)
+	if !ok {
+		return nil, fmt.Errorf("not an index expression")
+	}
+
+	object, err := it.Evaluate(index.Object)
+	if err != nil {
+		return nil, err
+	}
+	i, err := it.Evaluate(index.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch object := object.(type) {
+	case string:
+		value, err = stringIndex(object, i)
+	case *List:
+		value, err = object.Get(i)
+	case *Map:
+		value, err = object.Get(i)
+	case *Deque:
+		value, err = object.Get(i)
+	case *Tree:
+		value, err = object.Get(i)
+	default:
+		err = fmt.Errorf("only strings, lists, maps, deques and trees can be indexed")
+	}
+	if err != nil {
+		return nil, it.locate(index.Bracket, err)
+	}
+	return value, nil
+}
+
+func (it *Interpreter) VisitIndexSet(ex expr.Expr) (any, error) {
+	set, ok := ex.(expr.IndexSet)
+	if !ok {
+		return nil, fmt.Errorf("not an index set expression")
+	}
+
+	object, err := it.Evaluate(set.Object)
+	if err != nil {
+		return nil, err
+	}
+	i, err := it.Evaluate(set.Index)
+	if err != nil {
+		return nil, err
+	}
+	value, err := it.Evaluate(set.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch object := object.(type) {
+	case string:
+		err = fmt.Errorf("strings can't be modified, build a new one instead")
+	case *List:
+		err = object.Set(i, value)
+	case *Map:
+		err = object.Set(i, value)
+	case *Deque:
+		err = object.Set(i, value)
+	case *Tree:
+		err = object.Set(i, value)
+	default:
+		err = fmt.Errorf("only strings, lists, maps, deques and trees can be indexed")
+	}
+	if err != nil {
+		return nil, it.locate(set.Bracket, err)
+	}
+	return value, nil
+}
+
+func (it *Interpreter) VisitSlice(ex expr.Expr) (any, error) {
+	slice, ok := ex.(expr.Slice)
+	if !ok {
+		return nil, fmt.Errorf("not a slice expression")
+	}
+
+	object, err := it.Evaluate(slice.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bounds that were left out stay nil, which sliceBounds treats as the start or end
+	var start, end any
+	if slice.Start != nil {
+		if start, err = it.Evaluate(slice.Start); err != nil {
+			return nil, err
+		}
+	}
+	if slice.End != nil {
+		if end, err = it.Evaluate(slice.End); err != nil {
+			return nil, err
+		}
+	}
+
+	var value any
+	switch object := object.(type) {
+	case string:
+		value, err = stringSlice(object, start, end)
+	case *List:
+		value, err = object.Slice(start, end)
+	default:
+		err = fmt.Errorf("only strings and lists can be sliced")
+	}
+	if err != nil {
+		return nil, it.locate(slice.Bracket, err)
+	}
+	return value, nil
+}
+
+func (it *Interpreter) VisitThis(ex expr.Expr) (any, error) {
+	this, ok := ex.(*expr.This)
+	if !ok {
+		return nil, fmt.Errorf("not a this expression")
+	}
+	return it.lookUpVariable(this.Keyword, this)
+}
+
+func (it *Interpreter) VisitSuper(ex expr.Expr) (any, error) {
+	super, ok := ex.(*expr.Super)
+	if !ok {
+		return nil, fmt.Errorf("not a super expression")
+	}
+
+	// 'this' always lives in the environment right inside of the one holding 'super'
+	distance := it.locals[super]
+	v, err := it.environment.GetAt(distance, super.Keyword)
+	if err != nil {
+		return nil, err
+	}
+	superclass := v.(*Class)
+	v, err = it.environment.GetAt(distance-1, lexer.Token{Type: lexer.THIS, Lexeme: "this", Line: super.Keyword.Line})
+	if err != nil {
+		return nil, err
+	}
+	instance := v.(*Instance)
+
+	method, ok := superclass.FindMethod(super.Method.Lexeme)
+	if !ok {
+		return nil, it.error(super.Method, fmt.Sprintf("undefined property: '%s'", super.Method.Lexeme))
+	}
+	return method.Bind(instance), nil
+}
+
 func (it *Interpreter) VisitPrimary(ex expr.Expr) (any, error) {
 	primary, ok := ex.(expr.Primary)
 	if !ok {
@@ -292,16 +757,81 @@ func (it *Interpreter) VisitPrimary(ex expr.Expr) (any, error) {
 	return primary.Value, nil
 }
 
+func (it *Interpreter) VisitList(ex expr.Expr) (any, error) {
+	list, ok := ex.(expr.List)
+	if !ok {
+		return nil, fmt.Errorf("not a list expression")
+	}
+
+	elements := make([]any, 0, len(list.Elements))
+	for _, element := range list.Elements {
+		value, err := it.Evaluate(element)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, value)
+	}
+	return NewList(elements), nil
+}
+
+func (it *Interpreter) VisitMap(ex expr.Expr) (any, error) {
+	m, ok := ex.(expr.Map)
+	if !ok {
+		return nil, fmt.Errorf("not a map expression")
+	}
+
+	result := NewMap()
+	for i := range m.Keys {
+		key, err := it.Evaluate(m.Keys[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := it.Evaluate(m.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := result.Set(key, value); err != nil {
+			return nil, it.locate(m.Brace, err)
+		}
+	}
+	return result, nil
+}
+
+func (it *Interpreter) VisitInterpolation(ex expr.Expr) (any, error) {
+	interpolation, ok := ex.(expr.Interpolation)
+	if !ok {
+		return nil, fmt.Errorf("not an interpolation expression")
+	}
+
+	var result strings.Builder
+	for _, part := range interpolation.Parts {
+		value, err := it.Evaluate(part)
+		if err != nil {
+			return nil, err
+		}
+		result.WriteString(Stringify(value))
+	}
+	return result.String(), nil
+}
+
 func (it *Interpreter) VisitVariable(ex expr.Expr) (any, error) {
-	variable, ok := ex.(expr.Variable)
+	variable, ok := ex.(*expr.Variable)
 	if !ok {
 		return nil, fmt.Errorf("not a variable expression")
 	}
-	val, err := it.environment.Get(variable.Name)
-	if err != nil {
-		return nil, err
+	return it.lookUpVariable(variable.Name, variable)
+}
+
+// Anything the resolver didn't bind to a local scope must be a global
+func (it *Interpreter) lookUpVariable(name lexer.Token, ex expr.Expr) (any, error) {
+	var value any
+	var err error
+	if depth, ok := it.locals[ex]; ok {
+		value, err = it.environment.GetAt(depth, name)
+	} else {
+		value, err = it.globals.Get(name)
 	}
-	return val, nil
+	return value, it.locate(name, err)
 }
 
 func (it *Interpreter) VisitGrouping(ex expr.Expr) (any, error) {
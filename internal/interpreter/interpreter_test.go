@@ -1,11 +1,16 @@
 package interpreter
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/maxcelant/kiwi/internal/diagnostics"
 	"github.com/maxcelant/kiwi/internal/env"
 	"github.com/maxcelant/kiwi/internal/expr"
 	"github.com/maxcelant/kiwi/internal/lexer"
+	"github.com/maxcelant/kiwi/internal/parser"
+	"github.com/maxcelant/kiwi/internal/resolver"
 	"github.com/maxcelant/kiwi/internal/stmt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -23,6 +28,38 @@ var _ = Describe("Interpreter", func() {
 		it = New(nil, env.New(nil))
 	})
 
+	// Some behaviour is easier to read as source than as hand built trees, so these
+	// run the whole pipeline and let the specs inspect the globals afterwards
+	run := func(source string) error {
+		tokens, err := lexer.New(source).Scan()
+		Expect(err).To(BeNil())
+		stmts, err := parser.New(tokens).Parse()
+		Expect(err).To(BeNil())
+		locals, err := resolver.New().Resolve(stmts)
+		Expect(err).To(BeNil())
+		it.Resolve(locals)
+		for _, st := range stmts {
+			if err := it.Execute(st); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	global := func(name string) any {
+		value, err := it.environment.Get(lexer.Token{Type: lexer.IDENTIFIER, Lexeme: name})
+		Expect(err).To(BeNil())
+		return value
+	}
+
+	// Builds `left operator right` out of literal operands
+	binary := func(left any, operator lexer.TokenType, lexeme string, right any) expr.Expr {
+		return expr.Binary{
+			Left:     expr.Primary{Value: left},
+			Operator: lexer.Token{Type: operator, Lexeme: lexeme, Line: 1},
+			Right:    expr.Primary{Value: right},
+		}
+	}
+
 	Describe("Visit Primary Expr", func() {
 		When("the parse tree has a single primary number node", func() {
 			It("should return the value", func() {
@@ -427,7 +464,7 @@ var _ = Describe("Interpreter", func() {
 				}
 				_, err := it.Evaluate(node)
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(ContainSubstring("operands must be a number"))
+				Expect(err.Error()).To(ContainSubstring("operands must be two numbers, strings or lists for greater than operation"))
 			})
 		})
 
@@ -440,7 +477,7 @@ var _ = Describe("Interpreter", func() {
 				}
 				_, err := it.Evaluate(node)
 				Expect(err).ToNot(BeNil())
-				Expect(err.Error()).To(ContainSubstring("operands must be a number"))
+				Expect(err.Error()).To(ContainSubstring("operands must be two numbers, strings or lists for greater than operation"))
 			})
 		})
 
@@ -742,7 +779,7 @@ var _ = Describe("Interpreter", func() {
 			When("the parse tree has an assign expression with a valid variable", func() {
 				It("should assign the value to the variable in the environment", func() {
 					it.environment.Define("a", 10)
-					node := expr.Assign{
+					node := &expr.Assign{
 						Name:  lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "a", Line: 1},
 						Value: expr.Primary{Value: 20},
 					}
@@ -757,7 +794,7 @@ var _ = Describe("Interpreter", func() {
 
 			When("the parse tree has an assign expression with an undefined variable", func() {
 				It("should return an error", func() {
-					node := expr.Assign{
+					node := &expr.Assign{
 						Name:  lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "b", Line: 1},
 						Value: expr.Primary{Value: 20},
 					}
@@ -770,7 +807,7 @@ var _ = Describe("Interpreter", func() {
 			When("the parse tree has an assign expression with an invalid value", func() {
 				It("should return an error", func() {
 					it.environment.Define("c", 10)
-					node := expr.Assign{
+					node := &expr.Assign{
 						Name: lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "c", Line: 1},
 						Value: expr.Binary{
 							Left:     expr.Primary{Value: 1},
@@ -895,4 +932,1540 @@ var _ = Describe("Interpreter", func() {
 		})
 	})
 
+	Describe("Visit If Stmt", func() {
+		assignBranch := func(value any) stmt.Stmt {
+			return stmt.Expression{
+				Expression: &expr.Assign{
+					Name:  lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "branch", Line: 1},
+					Value: expr.Primary{Value: value},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			it.environment.Define("branch", "none")
+		})
+
+		DescribeTable("executes the branch selected by the truthiness of the condition",
+			func(condition any, withElse bool, expected any) {
+				node := stmt.If{
+					Condition:  expr.Primary{Value: condition},
+					ThenBranch: assignBranch("then"),
+				}
+				if withElse {
+					node.ElseBranch = assignBranch("else")
+				}
+				err := it.Execute(node)
+				Expect(err).To(BeNil())
+				value, err := it.environment.Get(lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "branch"})
+				Expect(err).To(BeNil())
+				Expect(value).To(Equal(expected))
+			},
+			Entry("true condition without else", true, false, "then"),
+			Entry("false condition without else", false, false, "none"),
+			Entry("true condition with else", true, true, "then"),
+			Entry("false condition with else", false, true, "else"),
+			Entry("nil condition with else", nil, true, "else"),
+			Entry("zero condition with else", 0, true, "then"),
+			Entry("string condition with else", "", true, "then"),
+		)
+
+		When("the parse tree has an if statement with an invalid condition", func() {
+			It("should return an error", func() {
+				node := stmt.If{
+					Condition: expr.Binary{
+						Left:     expr.Primary{Value: 1},
+						Operator: lexer.Token{Type: lexer.PLUS, Lexeme: "+", Line: 1},
+						Right:    expr.Primary{Value: "invalid"},
+					},
+					ThenBranch: assignBranch("then"),
+				}
+				err := it.Execute(node)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("operands must both be a numbers or strings for add operation"))
+			})
+		})
+	})
+
+	Describe("Visit While Stmt", func() {
+		counter := lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "i", Line: 1}
+
+		When("the parse tree has a while statement that counts up", func() {
+			It("should execute the body until the condition is falsey", func() {
+				it.environment.Define("i", 0)
+				node := stmt.While{
+					Condition: expr.Binary{
+						Left:     &expr.Variable{Name: counter},
+						Operator: lexer.Token{Type: lexer.LESS, Lexeme: "<", Line: 1},
+						Right:    expr.Primary{Value: 5},
+					},
+					Body: stmt.Expression{
+						Expression: &expr.Assign{
+							Name: counter,
+							Value: expr.Binary{
+								Left:     &expr.Variable{Name: counter},
+								Operator: lexer.Token{Type: lexer.PLUS, Lexeme: "+", Line: 1},
+								Right:    expr.Primary{Value: 1},
+							},
+						},
+					},
+				}
+				err := it.Execute(node)
+				Expect(err).To(BeNil())
+				value, err := it.environment.Get(counter)
+				Expect(err).To(BeNil())
+				Expect(value).To(Equal(5))
+			})
+		})
+
+		When("the parse tree has a while statement with a falsey condition", func() {
+			It("should never execute the body", func() {
+				it.environment.Define("i", 0)
+				node := stmt.While{
+					Condition: expr.Primary{Value: nil},
+					Body: stmt.Expression{
+						Expression: &expr.Assign{Name: counter, Value: expr.Primary{Value: 1}},
+					},
+				}
+				err := it.Execute(node)
+				Expect(err).To(BeNil())
+				value, err := it.environment.Get(counter)
+				Expect(err).To(BeNil())
+				Expect(value).To(Equal(0))
+			})
+		})
+
+		When("the parse tree has a while statement whose body fails", func() {
+			It("should stop looping and return the error", func() {
+				node := stmt.While{
+					Condition: expr.Primary{Value: true},
+					Body: stmt.Expression{
+						Expression: &expr.Variable{Name: lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "undefined", Line: 1}},
+					},
+				}
+				err := it.Execute(node)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("undefined variable: 'undefined'"))
+			})
+		})
+	})
+
+	Describe("Visit Break and Continue Stmt", func() {
+		counter := lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "i", Line: 1}
+		total := lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "total", Line: 1}
+		increment := func(name lexer.Token, by expr.Expr) expr.Expr {
+			return &expr.Assign{
+				Name: name,
+				Value: expr.Binary{
+					Left:     &expr.Variable{Name: name},
+					Operator: lexer.Token{Type: lexer.PLUS, Lexeme: "+", Line: 1},
+					Right:    by,
+				},
+			}
+		}
+		counterEquals := func(n int) expr.Expr {
+			return expr.Binary{
+				Left:     &expr.Variable{Name: counter},
+				Operator: lexer.Token{Type: lexer.EQUAL_EQUAL, Lexeme: "==", Line: 1},
+				Right:    expr.Primary{Value: n},
+			}
+		}
+
+		BeforeEach(func() {
+			it.environment.Define("i", 0)
+			it.environment.Define("total", 0)
+		})
+
+		When("the parse tree has a break nested inside blocks of a while body", func() {
+			It("should exit the loop and restore the enclosing environment", func() {
+				global := it.environment
+				node := stmt.While{
+					Condition: expr.Primary{Value: true},
+					Body: stmt.Block{
+						Statements: []stmt.Stmt{
+							stmt.Expression{Expression: increment(counter, expr.Primary{Value: 1})},
+							stmt.Block{
+								Statements: []stmt.Stmt{
+									stmt.If{
+										Condition:  counterEquals(3),
+										ThenBranch: stmt.Block{Statements: []stmt.Stmt{stmt.Break{}}},
+									},
+								},
+							},
+						},
+					},
+				}
+				err := it.Execute(node)
+				Expect(err).To(BeNil())
+				Expect(it.environment).To(BeIdenticalTo(global))
+				value, err := it.environment.Get(counter)
+				Expect(err).To(BeNil())
+				Expect(value).To(Equal(3))
+			})
+		})
+
+		When("the parse tree has a continue inside a loop with an increment", func() {
+			It("should skip the rest of the body but still run the increment", func() {
+				// for (; i < 5; i = i + 1) { if (i == 2) continue; total = total + i; }
+				node := stmt.While{
+					Condition: expr.Binary{
+						Left:     &expr.Variable{Name: counter},
+						Operator: lexer.Token{Type: lexer.LESS, Lexeme: "<", Line: 1},
+						Right:    expr.Primary{Value: 5},
+					},
+					Body: stmt.Block{
+						Statements: []stmt.Stmt{
+							stmt.If{Condition: counterEquals(2), ThenBranch: stmt.Continue{}},
+							stmt.Expression{Expression: increment(total, &expr.Variable{Name: counter})},
+						},
+					},
+					Increment: increment(counter, expr.Primary{Value: 1}),
+				}
+				err := it.Execute(node)
+				Expect(err).To(BeNil())
+				value, err := it.environment.Get(total)
+				Expect(err).To(BeNil())
+				Expect(value).To(Equal(8))
+			})
+		})
+
+		When("the parse tree has a break inside an inner loop", func() {
+			It("should only exit the innermost loop", func() {
+				inner := stmt.While{
+					Condition: expr.Primary{Value: true},
+					Body: stmt.Block{
+						Statements: []stmt.Stmt{
+							stmt.Expression{Expression: increment(total, expr.Primary{Value: 1})},
+							stmt.Break{},
+						},
+					},
+				}
+				node := stmt.While{
+					Condition: expr.Binary{
+						Left:     &expr.Variable{Name: counter},
+						Operator: lexer.Token{Type: lexer.LESS, Lexeme: "<", Line: 1},
+						Right:    expr.Primary{Value: 3},
+					},
+					Body:      inner,
+					Increment: increment(counter, expr.Primary{Value: 1}),
+				}
+				err := it.Execute(node)
+				Expect(err).To(BeNil())
+				value, err := it.environment.Get(total)
+				Expect(err).To(BeNil())
+				Expect(value).To(Equal(3))
+			})
+		})
+	})
+
+	Describe("Visit Function Declaration and Call Expr", func() {
+		ident := func(name string) lexer.Token {
+			return lexer.Token{Type: lexer.IDENTIFIER, Lexeme: name, Line: 1}
+		}
+		call := func(name string, args ...expr.Expr) expr.Expr {
+			return expr.Call{
+				Callee:    &expr.Variable{Name: ident(name)},
+				Paren:     lexer.Token{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+				Arguments: args,
+			}
+		}
+
+		When("the parse tree declares a function and calls it with arguments", func() {
+			It("should bind the parameters and return the value", func() {
+				decl := stmt.Function{
+					Name:   ident("add"),
+					Params: []lexer.Token{ident("a"), ident("b")},
+					Body: []stmt.Stmt{
+						stmt.Return{Value: expr.Binary{
+							Left:     &expr.Variable{Name: ident("a")},
+							Operator: lexer.Token{Type: lexer.PLUS, Lexeme: "+", Line: 1},
+							Right:    &expr.Variable{Name: ident("b")},
+						}},
+					},
+				}
+				locals, err := resolver.New().Resolve([]stmt.Stmt{decl})
+				Expect(err).To(BeNil())
+				it.Resolve(locals)
+				err = it.Execute(decl)
+				Expect(err).To(BeNil())
+				actual, err := it.Evaluate(call("add", expr.Primary{Value: 2}, expr.Primary{Value: 3}))
+				Expect(err).To(BeNil())
+				Expect(actual).To(Equal(5))
+			})
+		})
+
+		When("the parse tree calls a function without a return statement", func() {
+			It("should return nil", func() {
+				err := it.Execute(stmt.Function{Name: ident("noop"), Params: []lexer.Token{}})
+				Expect(err).To(BeNil())
+				actual, err := it.Evaluate(call("noop"))
+				Expect(err).To(BeNil())
+				Expect(actual).To(BeNil())
+			})
+		})
+
+		When("the parse tree returns from inside a loop nested in blocks", func() {
+			It("should unwind out of the loop and restore the caller's environment", func() {
+				global := it.environment
+				decl := stmt.Function{
+					Name:   ident("find"),
+					Params: []lexer.Token{},
+					Body: []stmt.Stmt{
+						stmt.While{
+							Condition: expr.Primary{Value: true},
+							Body: stmt.Block{
+								Statements: []stmt.Stmt{
+									stmt.Block{
+										Statements: []stmt.Stmt{
+											stmt.Return{Value: expr.Primary{Value: "found"}},
+										},
+									},
+								},
+							},
+						},
+						stmt.Return{Value: expr.Primary{Value: "not found"}},
+					},
+				}
+				err := it.Execute(decl)
+				Expect(err).To(BeNil())
+				actual, err := it.Evaluate(call("find"))
+				Expect(err).To(BeNil())
+				Expect(actual).To(Equal("found"))
+				Expect(it.environment).To(BeIdenticalTo(global))
+			})
+		})
+
+		When("the parse tree calls a function with the wrong number of arguments", func() {
+			It("should return an arity error", func() {
+				err := it.Execute(stmt.Function{Name: ident("one"), Params: []lexer.Token{ident("a")}})
+				Expect(err).To(BeNil())
+				_, err = it.Evaluate(call("one"))
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("expected 1 arguments but got 0"))
+			})
+		})
+
+		When("the parse tree calls a value that is not callable", func() {
+			It("should return an error", func() {
+				it.environment.Define("notfn", "string")
+				_, err := it.Evaluate(call("notfn"))
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("can only call functions and classes"))
+			})
+		})
+	})
+
+	Describe("Closures", func() {
+		When("a function returns a counter that closes over a local", func() {
+			It("should keep incrementing the captured variable across calls", func() {
+				Expect(run(`
+					fn makeCounter() {
+						var count = 0;
+						fn counter() {
+							count = count + 1;
+							return count;
+						}
+						return counter;
+					}
+					var counter = makeCounter();
+					counter();
+					counter();
+					var result = counter();
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal(3))
+			})
+		})
+
+		When("two counters are created from the same generator", func() {
+			It("should give each counter its own captured variable", func() {
+				Expect(run(`
+					fn makeCounter() {
+						var count = 0;
+						fn counter() {
+							count = count + 1;
+							return count;
+						}
+						return counter;
+					}
+					var a = makeCounter();
+					var b = makeCounter();
+					a();
+					a();
+					var first = a();
+					var second = b();
+				`)).To(Succeed())
+				Expect(global("first")).To(Equal(3))
+				Expect(global("second")).To(Equal(1))
+			})
+		})
+
+		When("a closure shadows a captured variable with a parameter", func() {
+			It("should read the parameter instead of the outer variable", func() {
+				Expect(run(`
+					var x = "global";
+					fn outer() {
+						var x = "outer";
+						fn inner(x) {
+							return x;
+						}
+						return inner;
+					}
+					var shadowed = outer()("param");
+					var untouched = x;
+				`)).To(Succeed())
+				Expect(global("shadowed")).To(Equal("param"))
+				Expect(global("untouched")).To(Equal("global"))
+			})
+		})
+
+		When("a closure outlives the block it was declared in", func() {
+			It("should still be able to read and write the block's variables", func() {
+				Expect(run(`
+					var get;
+					var set;
+					{
+						var secret = 1;
+						fn getter() { return secret; }
+						fn setter(v) { secret = v; }
+						get = getter;
+						set = setter;
+					}
+					set(42);
+					var result = get();
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal(42))
+			})
+		})
+
+		When("a variable is shadowed after a closure was declared", func() {
+			It("should keep reading the variable that was in scope at the declaration", func() {
+				Expect(run(`
+					var a = "global";
+					var first;
+					var second;
+					{
+						fn show() { return a; }
+						first = show();
+						var a = "block";
+						second = show();
+					}
+				`)).To(Succeed())
+				Expect(global("first")).To(Equal("global"))
+				Expect(global("second")).To(Equal("global"))
+			})
+		})
+	})
+
+	Describe("Classes", func() {
+		When("a class is called", func() {
+			It("should create an instance of that class", func() {
+				Expect(run(`
+					class Node {}
+					var node = Node();
+				`)).To(Succeed())
+				Expect(global("node")).To(BeAssignableToTypeOf(&Instance{}))
+				Expect(Stringify(global("Node"))).To(Equal("Node"))
+				Expect(Stringify(global("node"))).To(Equal("<Node instance>"))
+			})
+		})
+
+		When("fields are set on an instance", func() {
+			It("should read back the stored values", func() {
+				Expect(run(`
+					class Pair {}
+					var pair = Pair();
+					pair.first = 1;
+					pair.second = pair.first + 1;
+					var result = pair.second;
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal(2))
+			})
+		})
+
+		When("a method reads fields through this", func() {
+			It("should see the instance it was called on", func() {
+				Expect(run(`
+					class Counter {
+						init(start) {
+							this.count = start;
+						}
+						increment() {
+							this.count = this.count + 1;
+							return this.count;
+						}
+					}
+					var counter = Counter(10);
+					counter.increment();
+					var result = counter.increment();
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal(12))
+			})
+		})
+
+		When("a method is pulled off of its instance", func() {
+			It("should stay bound to the original instance", func() {
+				Expect(run(`
+					class Person {
+						init(name) { this.name = name; }
+						greet() { return "hi " + this.name; }
+					}
+					var greet = Person("ada").greet;
+					var result = greet();
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal("hi ada"))
+			})
+		})
+
+		When("an initializer is called directly or returns early", func() {
+			It("should still return the instance", func() {
+				Expect(run(`
+					class Box {
+						init(value) {
+							this.value = value;
+							if (value == 0) return;
+							this.value = value * 2;
+						}
+					}
+					var box = Box(0);
+					var same = box.init(3);
+				`)).To(Succeed())
+				Expect(global("same")).To(BeIdenticalTo(global("box")))
+				box := global("box").(*Instance)
+				Expect(box.fields["value"]).To(Equal(6))
+			})
+		})
+
+		When("a class is called with the wrong number of initializer arguments", func() {
+			It("should return an arity error", func() {
+				err := run(`
+					class Point {
+						init(x, y) {}
+					}
+					Point(1);
+				`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("expected 2 arguments but got 1"))
+			})
+		})
+
+		When("an undefined property is read", func() {
+			It("should return an error", func() {
+				err := run(`
+					class Empty {}
+					Empty().missing;
+				`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("undefined property: 'missing'"))
+			})
+		})
+
+		When("a property is read from a value that is not an instance", func() {
+			It("should return an error", func() {
+				err := run(`
+					var text = "abc";
+					text.length;
+				`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("only instances have properties"))
+			})
+		})
+
+		DescribeTable("compares instances and nil for equality",
+			func(source string, expected bool) {
+				err := run(`
+					class Node {}
+					var a = Node();
+					var b = Node();
+					var m = {"k": 1};
+				` + "var out = " + source + ";")
+				Expect(err).To(BeNil())
+				Expect(global("out")).To(Equal(expected))
+			},
+			Entry("an instance with itself", `a == a`, true),
+			Entry("two different instances", `a == b`, false),
+			Entry("an instance with nil", `a != nil`, true),
+			Entry("nil with nil", `nil == nil`, true),
+			Entry("a map with itself", `m == m`, true),
+			Entry("two maps with the same entries", `{"k": 1} != {"k": 1}`, true),
+			Entry("values of different types", `1 == "1"`, false),
+			Entry("a class with itself", `Node == Node`, true),
+		)
+
+		When("a linked list is walked until it runs out", func() {
+			It("stops at nil", func() {
+				err := run(`
+					class Node {
+						init(value, next) {
+							this.value = value;
+							this.next = next;
+						}
+					}
+					var n = Node(1, Node(2, Node(3, nil)));
+					var total = 0;
+					while (n != nil) {
+						total = total + n.value;
+						n = n.next;
+					}
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("total")).To(Equal(6))
+			})
+		})
+	})
+
+	Describe("Inheritance", func() {
+		When("a subclass doesn't override a method", func() {
+			It("should find the method on the superclass", func() {
+				Expect(run(`
+					class Animal {
+						speak() { return "..."; }
+					}
+					class Dog < Animal {}
+					var result = Dog().speak();
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal("..."))
+			})
+		})
+
+		When("a subclass overrides a method and calls super", func() {
+			It("should run the superclass method bound to the same instance", func() {
+				Expect(run(`
+					class Animal {
+						init(name) { this.name = name; }
+						speak() { return this.name + " makes a sound"; }
+					}
+					class Dog < Animal {
+						init(name) {
+							super.init(name);
+							this.tricks = 0;
+						}
+						speak() { return super.speak() + " and barks"; }
+					}
+					var result = Dog("rex").speak();
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal("rex makes a sound and barks"))
+			})
+		})
+
+		When("super is used in a class further down the chain", func() {
+			It("should resolve super lexically instead of through the instance", func() {
+				Expect(run(`
+					class A {
+						method() { return "A"; }
+					}
+					class B < A {
+						method() { return "B"; }
+						test() { return super.method(); }
+					}
+					class C < B {}
+					var result = C().test();
+				`)).To(Succeed())
+				Expect(global("result")).To(Equal("A"))
+			})
+		})
+
+		When("a class inherits from a value that is not a class", func() {
+			It("should return an error", func() {
+				err := run(`
+					var NotAClass = "nope";
+					class Child < NotAClass {}
+				`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("superclass must be a class"))
+			})
+		})
+
+		When("super is used to call a method that doesn't exist", func() {
+			It("should return an error", func() {
+				err := run(`
+					class A {}
+					class B < A {
+						test() { return super.missing(); }
+					}
+					B().test();
+				`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("undefined property: 'missing'"))
+			})
+		})
+	})
+
+	Describe("Floating-point numbers", func() {
+		DescribeTable("promotes to float64 whenever either operand is a float",
+			func(node expr.Expr, expected any) {
+				actual, err := it.Evaluate(node)
+				Expect(err).To(BeNil())
+				Expect(actual).To(Equal(expected))
+			},
+			Entry("int plus int stays an int", binary(1, lexer.PLUS, "+", 2), 3),
+			Entry("int plus float", binary(1, lexer.PLUS, "+", 0.5), 1.5),
+			Entry("float minus int", binary(2.5, lexer.MINUS, "-", 1), 1.5),
+			Entry("float times float", binary(1.5, lexer.STAR, "*", 2.0), 3.0),
+			Entry("int divided by int truncates", binary(7, lexer.SLASH, "/", 2), 3),
+			Entry("int divided by float", binary(7, lexer.SLASH, "/", 2.0), 3.5),
+			Entry("int less than float", binary(1, lexer.LESS, "<", 1.5), true),
+			Entry("float greater or equal to int", binary(2.0, lexer.GREATER_EQ, ">=", 2), true),
+			Entry("int equal to the same float", binary(2, lexer.EQUAL_EQUAL, "==", 2.0), true),
+			Entry("int not equal to a different float", binary(2, lexer.BANG_EQ, "!=", 2.5), true),
+		)
+
+		When("the parse tree negates a float", func() {
+			It("should return the negated float", func() {
+				node := expr.Unary{
+					Operator: lexer.Token{Type: lexer.MINUS, Lexeme: "-", Line: 1},
+					Right:    expr.Primary{Value: 1.25},
+				}
+				actual, err := it.Evaluate(node)
+				Expect(err).To(BeNil())
+				Expect(actual).To(Equal(-1.25))
+			})
+		})
+
+		When("the parse tree divides a float by zero", func() {
+			It("should return an error", func() {
+				_, err := it.Evaluate(binary(1.5, lexer.SLASH, "/", 0))
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("division by zero"))
+			})
+		})
+
+		DescribeTable("stringifies floats without trailing zeros",
+			func(value float64, expected string) {
+				Expect(Stringify(value)).To(Equal(expected))
+			},
+			Entry("a float with a fraction", 2.50, "2.5"),
+			Entry("a whole float", 3.0, "3"),
+			Entry("a negative float", -0.125, "-0.125"),
+			Entry("a very large float", 1e21, "1e+21"),
+			Entry("a very small float", 1e-7, "1e-07"),
+		)
+	})
+
+	Describe("Modulo, floor division and exponent operators", func() {
+		DescribeTable("evaluates the operator",
+			func(node expr.Expr, expected any) {
+				actual, err := it.Evaluate(node)
+				Expect(err).To(BeNil())
+				Expect(actual).To(Equal(expected))
+			},
+			Entry("int modulo int", binary(7, lexer.PERCENT, "%", 3), 1),
+			Entry("negative int modulo takes the sign of the divisor", binary(-1, lexer.PERCENT, "%", 5), 4),
+			Entry("float modulo int", binary(5.5, lexer.PERCENT, "%", 2), 1.5),
+			Entry("int floor divided by int", binary(7, lexer.TILDE_SLASH, "~/", 2), 3),
+			Entry("negative int floor divided rounds down", binary(-7, lexer.TILDE_SLASH, "~/", 2), -4),
+			Entry("float floor divided stays a float", binary(7.5, lexer.TILDE_SLASH, "~/", 2), 3.0),
+			Entry("int to the power of int", binary(2, lexer.STAR_STAR, "**", 10), 1024),
+			Entry("int to a negative power", binary(2, lexer.STAR_STAR, "**", -1), 0.5),
+			Entry("float to the power of int", binary(1.5, lexer.STAR_STAR, "**", 2), 2.25),
+			Entry("zero to the power of zero", binary(0, lexer.STAR_STAR, "**", 0), 1),
+		)
+
+		DescribeTable("returns an error when it would divide by zero",
+			func(node expr.Expr, message string) {
+				_, err := it.Evaluate(node)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("modulo", binary(7, lexer.PERCENT, "%", 0), "cannot perform modulo by zero"),
+			Entry("floor division", binary(7, lexer.TILDE_SLASH, "~/", 0), "cannot perform floor division by zero"),
+			Entry("float modulo", binary(7.5, lexer.PERCENT, "%", 0.0), "cannot perform modulo by zero"),
+			Entry("zero to a negative power", binary(0, lexer.STAR_STAR, "**", -1), "cannot raise zero to a negative power"),
+			Entry("float zero to a negative power", binary(0.0, lexer.STAR_STAR, "**", -1), "cannot raise zero to a negative power"),
+			Entry("zero to a negative float power", binary(0, lexer.STAR_STAR, "**", -0.5), "cannot raise zero to a negative power"),
+		)
+
+		When("the operands are not numbers", func() {
+			It("should return an error", func() {
+				_, err := it.Evaluate(binary("a", lexer.STAR_STAR, "**", 2))
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("operands must be a number for exponent operation"))
+			})
+		})
+
+		When("the program mixes the operators with unary minus", func() {
+			It("should apply power before negation and associate it to the right", func() {
+				err := run(`
+					var a = -2 ** 2;
+					var b = 2 ** 3 ** 2;
+					var c = 17 ~/ 5 * 5 + 17 % 5;
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("a")).To(Equal(-4))
+				Expect(global("b")).To(Equal(512))
+				Expect(global("c")).To(Equal(17))
+			})
+		})
+	})
+
+	Describe("Lists", func() {
+		When("the program indexes into a list", func() {
+			It("should count negative indexes back from the end", func() {
+				err := run(`
+					var xs = [10, 20, 30];
+					var first = xs[0];
+					var last = xs[-1];
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("first")).To(Equal(10))
+				Expect(global("last")).To(Equal(30))
+			})
+		})
+
+		When("the program assigns to an index", func() {
+			It("should update the list in place, even through another reference", func() {
+				err := run(`
+					var xs = [1, 2, 3];
+					fn clear(list) { list[-2] = 0; }
+					clear(xs);
+					var out = xs[1];
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("out")).To(Equal(0))
+			})
+		})
+
+		DescribeTable("returns an error naming the index and the length when out of range",
+			func(source string, message string) {
+				err := run(source)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("reading past the end", `var xs = [1, 2]; print xs[2];`, "index 2 out of range for list of length 2"),
+			Entry("reading before the start", `var xs = [1, 2]; print xs[-3];`, "index -3 out of range for list of length 2"),
+			Entry("writing past the end", `var xs = []; xs[0] = 1;`, "index 0 out of range for list of length 0"),
+		)
+
+		When("the program indexes with something other than an integer", func() {
+			It("should return an error", func() {
+				err := run(`var xs = [1]; print xs["0"];`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("list index must be an integer"))
+			})
+		})
+
+		When("the program indexes something other than a list", func() {
+			It("should return an error", func() {
+				err := run(`var n = 1; print n[0];`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("only strings, lists, maps, deques and trees can be indexed"))
+			})
+		})
+
+		DescribeTable("compares lists structurally",
+			func(source string, expected bool) {
+				err := run("var out = " + source + ";")
+				Expect(err).To(BeNil())
+				Expect(global("out")).To(Equal(expected))
+			},
+			Entry("equal elements", `[1, "a", true] == [1, "a", true]`, true),
+			Entry("ints and floats of the same value", `[1, 2] == [1.0, 2]`, true),
+			Entry("nested lists", `[[1], [2, 3]] == [[1], [2, 3]]`, true),
+			Entry("different lengths", `[1, 2] == [1, 2, 3]`, false),
+			Entry("different elements", `[1, 2] != [2, 1]`, true),
+		)
+
+		When("a list holds itself", func() {
+			It("prints the cycle as [...] instead of recursing forever", func() {
+				err := run(`var xs = [1, 2]; xs[0] = xs; var m = {"xs": xs}; m["m"] = m;`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("xs"))).To(Equal("[[...], 2]"))
+				Expect(Stringify(global("m"))).To(Equal(`{"xs": [[...], 2], "m": {...}}`))
+			})
+
+			It("compares it without recursing forever", func() {
+				err := run(`
+					var xs = [1]; xs[0] = xs;
+					var ys = [1]; ys[0] = ys;
+					var same = xs == ys;
+					var order = xs < ys;
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("same")).To(BeTrue())
+				Expect(global("order")).To(BeFalse())
+			})
+		})
+
+		DescribeTable("stringifies lists",
+			func(source string, expected string) {
+				err := run("var out = " + source + ";")
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("out"))).To(Equal(expected))
+			},
+			Entry("an empty list", `[]`, "[]"),
+			Entry("a list of numbers", `[1, 2.5, -3]`, "[1, 2.5, -3]"),
+			Entry("a list with strings and nil", `["a", nil, false]`, `["a", nil, false]`),
+			Entry("a nested list", `[[1, 2], [3]]`, "[[1, 2], [3]]"),
+		)
+	})
+
+	Describe("Maps", func() {
+		When("the program reads and writes keys", func() {
+			It("should support every hashable key type", func() {
+				err := run(`
+					var m = {"a": 1, 2: "two", true: [3], nil: 4};
+					m["a"] = m["a"] + 10;
+					var a = m["a"];
+					var two = m[2];
+					var t = m[true][0];
+					var n = m[nil];
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("a")).To(Equal(11))
+				Expect(global("two")).To(Equal("two"))
+				Expect(global("t")).To(Equal(3))
+				Expect(global("n")).To(Equal(4))
+			})
+		})
+
+		DescribeTable("returns an error",
+			func(source string, message string) {
+				err := run(source)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("reading a missing key", `var m = {}; print m["a"];`, `key "a" not found in map`),
+			Entry("using a list as a key", `var m = {}; m[[1]] = 1;`, "map keys must be a string, int, bool or nil, got list"),
+			Entry("using a float as a key", `var m = {1.5: 1};`, "map keys must be a string, int, bool or nil, got float"),
+			Entry("using a whole float as a key", `var m = {}; m[1.0] = 1;`, "got float"),
+			Entry("using a list as a key in a literal", `var m = {[1]: 2};`, "1:9: map keys must be a string, int, bool or nil, got list"),
+			Entry("passing something other than a map to a builtin", `keys([1]);`, "keys expects a map"),
+		)
+
+		When("the program uses the builtins", func() {
+			It("should iterate keys and values in insertion order", func() {
+				err := run(`
+					var m = {"b": 1, "a": 2};
+					m["c"] = 3;
+					m["b"] = 4;
+					var ks = keys(m);
+					var vs = values(m);
+				`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("ks"))).To(Equal(`["b", "a", "c"]`))
+				Expect(Stringify(global("vs"))).To(Equal("[4, 2, 3]"))
+			})
+
+			It("should report membership and remove keys", func() {
+				err := run(`
+					var m = {"a": 1};
+					var before = has(m, "a");
+					var removed = delete(m, "a");
+					var missing = delete(m, "a");
+					var after = has(m, "a");
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("before")).To(BeTrue())
+				Expect(global("removed")).To(BeTrue())
+				Expect(global("missing")).To(BeFalse())
+				Expect(global("after")).To(BeFalse())
+			})
+		})
+
+		DescribeTable("stringifies maps in insertion order",
+			func(source string, expected string) {
+				err := run("var out = " + source + ";")
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("out"))).To(Equal(expected))
+			},
+			Entry("an empty map", `{}`, "{}"),
+			Entry("a map of mixed keys", `{"z": 1, 1: nil, false: "no"}`, `{"z": 1, 1: nil, false: "no"}`),
+			Entry("a map holding a list", `{"xs": [1, 2]}`, `{"xs": [1, 2]}`),
+		)
+	})
+
+	Describe("Deques", func() {
+		When("values are pushed and popped at both ends", func() {
+			It("should behave like a queue and a stack at the same time", func() {
+				err := run(`
+					var d = deque();
+					pushBack(d, 2);
+					pushBack(d, 3);
+					pushFront(d, 1);
+					var front = peekFront(d);
+					var back = peekBack(d);
+					var size = len(d);
+					var first = popFront(d);
+					var last = popBack(d);
+					var rest = len(d);
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("front")).To(Equal(1))
+				Expect(global("back")).To(Equal(3))
+				Expect(global("size")).To(Equal(3))
+				Expect(global("first")).To(Equal(1))
+				Expect(global("last")).To(Equal(3))
+				Expect(global("rest")).To(Equal(1))
+			})
+		})
+
+		When("the ring buffer wraps around and grows", func() {
+			It("should keep the elements in order", func() {
+				d := NewDeque()
+				for i := 0; i < 6; i++ {
+					d.PushBack(i)
+				}
+				for i := 0; i < 4; i++ {
+					_, err := d.PopFront()
+					Expect(err).To(BeNil())
+				}
+				// head is now in the middle of the buffer, so these wrap around and then force a resize
+				for i := 6; i < 20; i++ {
+					d.PushBack(i)
+				}
+				d.PushFront(3)
+				expected := []any{}
+				for i := 3; i < 20; i++ {
+					expected = append(expected, i)
+				}
+				Expect(d.Elements()).To(Equal(expected))
+				Expect(d.Len()).To(Equal(17))
+			})
+		})
+
+		When("the program iterates over a deque by index", func() {
+			It("should walk from the front to the back", func() {
+				err := run(`
+					var d = deque();
+					for (var i = 0; i < 5; i = i + 1) pushFront(d, i);
+					var total = 0;
+					for (var i = 0; i < len(d); i = i + 1) total = total * 10 + d[i];
+					var last = d[-1];
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("total")).To(Equal(43210))
+				Expect(global("last")).To(Equal(0))
+			})
+		})
+
+		DescribeTable("returns an error",
+			func(source string, message string) {
+				err := run(source)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("popping an empty deque", `popBack(deque());`, "cannot pop from an empty deque"),
+			Entry("peeking an empty deque", `peekFront(deque());`, "cannot peek at an empty deque"),
+			Entry("indexing past the end", `var d = deque(); pushBack(d, 1); print d[1];`, "index 1 out of range for deque of length 1"),
+			Entry("passing something other than a deque", `pushBack([], 1);`, "pushBack expects a deque"),
+		)
+
+		When("a deque is stringified", func() {
+			It("should print its elements from front to back", func() {
+				err := run(`
+					var d = deque();
+					pushBack(d, "b");
+					pushFront(d, 1);
+				`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("d"))).To(Equal(`deque[1, "b"]`))
+			})
+		})
+	})
+
+	Describe("Heaps", func() {
+		DescribeTable("pops values in priority order",
+			func(source string, expected string) {
+				err := run(source + `
+					var out = [nil, nil, nil, nil];
+					for (var i = 0; i < 4; i = i + 1) out[i] = heapPop(h);
+				`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("out"))).To(Equal(expected))
+			},
+			Entry("a min-heap of numbers", `
+				var h = heap();
+				heapPush(h, 3); heapPush(h, 1.5); heapPush(h, 4); heapPush(h, 1);
+			`, "[1, 1.5, 3, 4]"),
+			Entry("a max-heap of strings", `
+				var h = maxHeap();
+				heapPush(h, "b"); heapPush(h, "d"); heapPush(h, "a"); heapPush(h, "c");
+			`, `["d", "c", "b", "a"]`),
+			Entry("a min-heap of lists compared lexicographically", `
+				var h = heap();
+				heapPush(h, [2, "x"]); heapPush(h, [1, "z"]); heapPush(h, [1, "y"]); heapPush(h, [1]);
+			`, `[[1], [1, "y"], [1, "z"], [2, "x"]]`),
+			Entry("a heap built from a list", `
+				var h = heapify(heap(), [5, 2, 8, 1]);
+			`, "[1, 2, 5, 8]"),
+			Entry("a heap with a user comparator", `
+				fn bySecond(a, b) { return a[1] > b[1]; }
+				var h = heapWith(bySecond);
+				heapify(h, [["a", 1], ["b", 3], ["c", 2], ["d", 4]]);
+			`, `[["d", 4], ["b", 3], ["c", 2], ["a", 1]]`),
+		)
+
+		When("the program peeks at a heap", func() {
+			It("should return the top without removing it", func() {
+				err := run(`
+					var h = heapify(maxHeap(), [1, 9, 4]);
+					var top = heapPeek(h);
+					var size = len(h);
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("top")).To(Equal(9))
+				Expect(global("size")).To(Equal(3))
+			})
+		})
+
+		When("a comparator closes over other variables", func() {
+			It("should call back into the interpreter with its closure", func() {
+				err := run(`
+					var dist = {"a": 7, "b": 2, "c": 5};
+					fn closer(x, y) { return dist[x] < dist[y]; }
+					var h = heapify(heapWith(closer), ["a", "b", "c"]);
+					var first = heapPop(h);
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("first")).To(Equal("b"))
+			})
+		})
+
+		DescribeTable("returns an error",
+			func(source string, message string) {
+				err := run(source)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("popping an empty heap", `heapPop(heap());`, "cannot pop from an empty heap"),
+			Entry("peeking an empty heap", `heapPeek(maxHeap());`, "cannot peek at an empty heap"),
+			Entry("mixing types that can't be ordered", `heapify(heap(), [1, "a"]);`, "cannot compare"),
+			Entry("a comparator with the wrong arity", `fn f(a) { return true; } heapWith(f);`, "heap comparator must take 2 arguments"),
+			Entry("a comparator that fails", `fn f(a, b) { return a < b; } heapify(heapWith(f), ["x", 1]);`, "operands must be two numbers, strings or lists for less than operation"),
+			Entry("pushing nil onto an empty heap", `heapPush(heap(), nil);`, "only numbers, strings and lists of them can be ordered, got nil"),
+			Entry("heapifying a single map", `heapify(maxHeap(), [{}]);`, "only numbers, strings and lists of them can be ordered, got map"),
+			Entry("pushing a list of bools", `heapPush(heap(), [true]);`, "only numbers, strings and lists of them can be ordered, got list"),
+		)
+
+		When("the first value pushed can't be ordered", func() {
+			It("leaves the heap empty and usable", func() {
+				err := run(`var h = heap(); heapPush(h, nil);`)
+				Expect(err).ToNot(BeNil())
+				err = run(`heapPush(h, 2); heapPush(h, 1); var top = heapPop(h); var size = len(h);`)
+				Expect(err).To(BeNil())
+				Expect(global("top")).To(Equal(1))
+				Expect(global("size")).To(Equal(1))
+			})
+		})
+
+		When("a comparator is given", func() {
+			It("doesn't need the values to be orderable", func() {
+				err := run(`
+					fn byFirst(a, b) { return a[0] < b[0]; }
+					var h = heapify(heapWith(byFirst), [[2, nil], [1, true]]);
+					var top = heapPop(h);
+				`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("top"))).To(Equal("[1, true]"))
+			})
+		})
+
+		When("a comparison fails partway through an operation", func() {
+			It("leaves the heap as it was before a push", func() {
+				err := run(`var h = heap(); heapPush(h, 1); heapPush(h, 5); heapPush(h, 3);`)
+				Expect(err).To(BeNil())
+				err = run(`heapPush(h, "a");`)
+				Expect(err).ToNot(BeNil())
+				Expect(Stringify(global("h"))).To(Equal("heap[1, 5, 3]"))
+			})
+
+			It("leaves the heap as it was before heapify", func() {
+				err := run(`var h = heap(); heapify(h, [4, 2, 6]);`)
+				Expect(err).To(BeNil())
+				err = run(`heapify(h, [1, 3, "a", 5]);`)
+				Expect(err).ToNot(BeNil())
+				Expect(Stringify(global("h"))).To(Equal("heap[2, 4, 6]"))
+			})
+
+			It("keeps the top of the heap when a pop fails", func() {
+				err := run(`
+					var strict = false;
+					fn order(a, b) {
+						if (strict) { return a < "x"; }
+						return a < b;
+					}
+					var h = heapWith(order);
+					heapify(h, [5, 3, 8, 1, 9, 2, 7]);
+				`)
+				Expect(err).To(BeNil())
+				before := Stringify(global("h"))
+
+				err = run(`strict = true; heapPop(h);`)
+				Expect(err).ToNot(BeNil())
+				Expect(Stringify(global("h"))).To(Equal(before))
+
+				err = run(`strict = false; var top = heapPop(h); var size = len(h);`)
+				Expect(err).To(BeNil())
+				Expect(global("top")).To(Equal(1))
+				Expect(global("size")).To(Equal(6))
+			})
+		})
+	})
+
+	Describe("Ordering", func() {
+		DescribeTable("compares strings and lists with the comparison operators",
+			func(source string, expected bool) {
+				err := run("var out = " + source + ";")
+				Expect(err).To(BeNil())
+				Expect(global("out")).To(Equal(expected))
+			},
+			Entry("strings byte-wise", `"apple" < "banana"`, true),
+			Entry("lists element by element", `[1, "b"] > [1, "a"]`, true),
+			Entry("a prefix before the longer list", `[1, 2] <= [1, 2, 0]`, true),
+			Entry("lists with ints and floats", `[2.0] >= [2]`, true),
+		)
+
+		When("a list holds elements that can't be ordered", func() {
+			It("should return an error naming both types", func() {
+				err := run(`print [1] < ["a"];`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("cannot compare number with string"))
+			})
+		})
+	})
+
+	Describe("Trees", func() {
+		When("keys are inserted and deleted in any order", func() {
+			It("should stay balanced and sorted", func() {
+				t := NewTree(true)
+				// A fixed shuffle of 0..199, stepping by a number coprime with 200
+				for i := 0; i < 200; i++ {
+					Expect(t.put((i*37)%200, nil)).To(Succeed())
+				}
+				for i := 0; i < 200; i += 2 {
+					removed, err := t.Delete(i)
+					Expect(err).To(BeNil())
+					Expect(removed).To(BeTrue())
+				}
+				expected := []any{}
+				for i := 1; i < 200; i += 2 {
+					expected = append(expected, i)
+				}
+				Expect(t.Keys()).To(Equal(expected))
+				Expect(t.Len()).To(Equal(100))
+				// An AVL tree of 100 nodes is at most ~1.44 log2(100) high
+				Expect(height(t.root)).To(BeNumerically("<=", 9))
+			})
+		})
+
+		When("the program uses a tree map", func() {
+			It("should support indexing and the map builtins in key order", func() {
+				err := run(`
+					var t = treeMap();
+					t["b"] = 2;
+					t["c"] = 3;
+					t["a"] = 1;
+					t["b"] = 20;
+					var b = t["b"];
+					var ks = keys(t);
+					var vs = values(t);
+					var removed = delete(t, "a");
+					var present = has(t, "a");
+					var size = len(t);
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("b")).To(Equal(20))
+				Expect(Stringify(global("ks"))).To(Equal(`["a", "b", "c"]`))
+				Expect(Stringify(global("vs"))).To(Equal("[1, 20, 3]"))
+				Expect(global("removed")).To(BeTrue())
+				Expect(global("present")).To(BeFalse())
+				Expect(global("size")).To(Equal(2))
+				Expect(Stringify(global("t"))).To(Equal(`treeMap{"b": 20, "c": 3}`))
+			})
+		})
+
+		When("the program queries a tree set", func() {
+			It("should answer ordered queries", func() {
+				err := run(`
+					var s = treeSet();
+					treeAdd(s, 10); treeAdd(s, 30); treeAdd(s, 20); treeAdd(s, 40); treeAdd(s, 20);
+					var low = treeMin(s);
+					var high = treeMax(s);
+					var floor = treeFloor(s, 25);
+					var ceiling = treeCeiling(s, 25);
+					var exact = treeFloor(s, 30);
+					var none = treeFloor(s, 5);
+					var rank = treeRank(s, 30);
+					var between = treeRange(s, 15, 30);
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("low")).To(Equal(10))
+				Expect(global("high")).To(Equal(40))
+				Expect(global("floor")).To(Equal(20))
+				Expect(global("ceiling")).To(Equal(30))
+				Expect(global("exact")).To(Equal(30))
+				Expect(global("none")).To(BeNil())
+				Expect(global("rank")).To(Equal(2))
+				Expect(Stringify(global("between"))).To(Equal("[20, 30]"))
+				Expect(Stringify(global("s"))).To(Equal("treeSet{10, 20, 30, 40}"))
+			})
+		})
+
+		When("the keys are intervals", func() {
+			It("should order them lexicographically", func() {
+				err := run(`
+					var s = treeSet();
+					treeAdd(s, [5, 9]); treeAdd(s, [1, 3]); treeAdd(s, [5, 6]);
+					var before = treeFloor(s, [5, 7]);
+				`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("before"))).To(Equal("[5, 6]"))
+			})
+		})
+
+		DescribeTable("returns an error",
+			func(source string, message string) {
+				err := run(source)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("reading a missing key", `var t = treeMap(); print t[1];`, "key 1 not found in tree map"),
+			Entry("mixing key types", `var t = treeMap(); t[1] = 1; t["a"] = 2;`, "cannot compare string with number"),
+			Entry("assigning to a tree set", `var s = treeSet(); s[1] = 1;`, "tree sets can't be assigned to"),
+			Entry("adding to a tree map", `treeAdd(treeMap(), 1);`, "treeAdd expects a tree set"),
+			Entry("adding a bool", `treeAdd(treeSet(), true);`, "only numbers, strings and lists of them can be ordered, got bool"),
+			Entry("adding nil", `treeAdd(treeSet(), nil);`, "only numbers, strings and lists of them can be ordered, got nil"),
+			Entry("keying a tree map by a map", `var t = treeMap(); t[{}] = 1;`, "only numbers, strings and lists of them can be ordered, got map"),
+			Entry("checking for a bool", `has(treeSet(), true);`, "only numbers, strings and lists of them can be ordered, got bool"),
+			Entry("the floor of nil", `treeFloor(treeSet(), nil);`, "only numbers, strings and lists of them can be ordered, got nil"),
+			Entry("the ceiling of a bool", `treeCeiling(treeSet(), false);`, "only numbers, strings and lists of them can be ordered, got bool"),
+			Entry("the rank of a list of bools", `treeRank(treeSet(), [true]);`, "only numbers, strings and lists of them can be ordered, got list"),
+			Entry("a range ending in nil", `treeRange(treeSet(), 1, nil);`, "only numbers, strings and lists of them can be ordered, got nil"),
+		)
+
+		When("the first key can't be ordered", func() {
+			It("leaves the tree empty and usable", func() {
+				err := run(`var s = treeSet(); treeAdd(s, true);`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("got bool"))
+				err = run(`var size = len(s); treeAdd(s, 1); var found = has(s, 1);`)
+				Expect(err).To(BeNil())
+				Expect(global("size")).To(Equal(0))
+				Expect(global("found")).To(BeTrue())
+			})
+		})
+
+		When("a comparison fails below the root", func() {
+			It("leaves the tree as it was", func() {
+				err := run(`var t = treeSet(); treeAdd(t, [0]); treeAdd(t, [1, 2]);`)
+				Expect(err).To(BeNil())
+				err = run(`treeAdd(t, [1, "x"]);`)
+				Expect(err).ToNot(BeNil())
+				err = run(`var size = len(t); treeAdd(t, [2]);`)
+				Expect(err).To(BeNil())
+				Expect(global("size")).To(Equal(2))
+				Expect(Stringify(global("t"))).To(Equal("treeSet{[0], [1, 2], [2]}"))
+			})
+
+			It("leaves the tree as it was when deleting", func() {
+				err := run(`var t = treeSet(); treeAdd(t, [0]); treeAdd(t, [1, 2]); treeAdd(t, [3]);`)
+				Expect(err).To(BeNil())
+				err = run(`delete(t, [1, "x"]);`)
+				Expect(err).ToNot(BeNil())
+				Expect(Stringify(global("t"))).To(Equal("treeSet{[0], [1, 2], [3]}"))
+			})
+		})
+	})
+
+	Describe("Strings", func() {
+		DescribeTable("indexes, slices and measures strings by character",
+			func(source string, expected any) {
+				err := run("var out = " + source + ";")
+				Expect(err).To(BeNil())
+				Expect(global("out")).To(Equal(expected))
+			},
+			Entry("the first character", `"kiwi"[0]`, "k"),
+			Entry("a negative index", `"kiwi"[-1]`, "i"),
+			Entry("a character past a multi-byte one", `"héllo"[2]`, "l"),
+			Entry("a slice with both bounds", `"héllo"[1:3]`, "él"),
+			Entry("a slice with no start", `"kiwi"[:2]`, "ki"),
+			Entry("a slice with no end", `"kiwi"[2:]`, "wi"),
+			Entry("a slice with negative bounds", `"kiwi"[-3:-1]`, "iw"),
+			Entry("a slice past the end is clamped", `"kiwi"[2:100]`, "wi"),
+			Entry("a slice with crossed bounds is empty", `"kiwi"[3:1]`, ""),
+			Entry("the length of an ascii string", `len("kiwi")`, 4),
+			Entry("the length of a multi-byte string", `len("日本語")`, 3),
+		)
+
+		DescribeTable("provides string helpers",
+			func(source string, expected any) {
+				err := run("var out = " + source + ";")
+				Expect(err).To(BeNil())
+				Expect(global("out")).To(Equal(expected))
+			},
+			Entry("trim", `trim("  kiwi  ")`, "kiwi"),
+			Entry("startsWith", `startsWith("kiwi", "ki")`, true),
+			Entry("endsWith", `endsWith("kiwi", "ki")`, false),
+			Entry("indexOf counts characters", `indexOf("héllo", "llo")`, 2),
+			Entry("indexOf with no match", `indexOf("kiwi", "z")`, -1),
+			Entry("replace", `replace("a-b-c", "-", "+")`, "a+b+c"),
+			Entry("upper", `upper("héllo")`, "HÉLLO"),
+			Entry("lower", `lower("KiWi")`, "kiwi"),
+			Entry("reverse keeps characters whole", `reverse("héllo")`, "olléh"),
+			Entry("repeat", `repeat("ab", 3)`, "ababab"),
+			Entry("join", `join(["a", 1, true], ", ")`, "a, 1, true"),
+		)
+
+		When("a string is split", func() {
+			It("should return a list of the parts", func() {
+				err := run(`
+					var parts = split("a,b,,c", ",");
+					var chars = split("héllo", "");
+				`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("parts"))).To(Equal(`["a", "b", "", "c"]`))
+				Expect(Stringify(global("chars"))).To(Equal(`["h", "é", "l", "l", "o"]`))
+			})
+		})
+
+		When("a list is sliced", func() {
+			It("should return a copy of that part of the list", func() {
+				err := run(`
+					var xs = [1, 2, 3, 4];
+					var ys = xs[1:-1];
+					ys[0] = 20;
+				`)
+				Expect(err).To(BeNil())
+				Expect(Stringify(global("ys"))).To(Equal("[20, 3]"))
+				Expect(Stringify(global("xs"))).To(Equal("[1, 2, 3, 4]"))
+			})
+		})
+
+		DescribeTable("returns an error",
+			func(source string, message string) {
+				err := run(source)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("indexing past the end", `print "héllo"[5];`, "index 5 out of range for string of length 5"),
+			Entry("assigning to an index", `var s = "kiwi"; s[0] = "K";`, "strings can't be modified"),
+			Entry("slicing with a non-integer", `print "kiwi"[0:"2"];`, "slice bounds must be integers"),
+			Entry("slicing a number", `print 12[0:1];`, "only strings and lists can be sliced"),
+			Entry("a helper given a non-string", `upper(1);`, "upper expects a string"),
+			Entry("repeating a negative number of times", `repeat("a", -1);`, "repeat expects a non-negative integer count"),
+		)
+	})
+
+	Describe("String interpolation", func() {
+		DescribeTable("stringifies and concatenates each part",
+			func(source string, expected string) {
+				err := run(`
+					var name = "kiwi";
+					var age = 2;
+					var xs = [1, "a"];
+					var out = ` + source + `;
+				`)
+				Expect(err).To(BeNil())
+				Expect(global("out")).To(Equal(expected))
+			},
+			Entry("a variable and an arithmetic expression", `"hello ${name}, you are ${age + 1}"`, "hello kiwi, you are 3"),
+			Entry("values that aren't strings", `"${nil} ${true} ${1.5} ${xs}"`, `nil true 1.5 [1, "a"]`),
+			Entry("a nested interpolation", `"${ "<${name}>" }"`, "<kiwi>"),
+			Entry("a map literal inside of the braces", `"${ {"k": age}["k"] }"`, "2"),
+			Entry("an escaped dollar sign", `"\${name}"`, "${name}"),
+		)
+
+		When("an interpolated expression fails", func() {
+			It("should return its error", func() {
+				err := run(`print "${undefinedName}";`)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("undefined variable"))
+			})
+		})
+	})
+
+	Describe("Runtime error locations", func() {
+		locate := func(source string) diagnostics.List {
+			err := run(source)
+			Expect(err).ToNot(BeNil())
+			return diagnostics.From(err)
+		}
+
+		DescribeTable("points the error at the token that caused it",
+			func(source string, line, column int, message string) {
+				list := locate(source)
+				Expect(list).To(HaveLen(1))
+				Expect(list[0].Span.Line).To(Equal(int64(line)))
+				Expect(list[0].Span.Column).To(Equal(int64(column)))
+				Expect(list[0].Message).To(Equal(message))
+			},
+			Entry("a binary operator", "var a = 1;\nvar b = a - \"x\";", 2, 11, "operands must be a number for subtract operation"),
+			Entry("a unary operator", "var a = -\"x\";", 1, 9, "operand must be a number"),
+			Entry("an undefined variable", "print 1;\nprint missing;", 2, 7, "undefined variable: 'missing'"),
+			Entry("calling something that isn't callable", "var a = 1;\na();", 2, 3, "can only call functions and classes"),
+			Entry("a native that fails", "upper(1);", 1, 8, "upper expects a string but got 1"),
+			Entry("an index that's out of range", "var l = [1];\nprint l[3];", 2, 10, "index 3 out of range for list of length 1"),
+		)
+
+		It("keeps the location inside of the function that failed", func() {
+			list := locate("fn f() {\n  return 1 / 0;\n}\nf();")
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].Span.Line).To(Equal(int64(2)))
+			Expect(list[0].Message).To(Equal("cannot perform division by zero"))
+		})
+	})
+
+	Describe("Runtime errors", func() {
+		runtimeError := func(source string) *RuntimeError {
+			err := run(source)
+			var runtimeErr *RuntimeError
+			Expect(errors.As(err, &runtimeErr)).To(BeTrue())
+			return runtimeErr
+		}
+
+		It("holds the token that failed without wrapping the message", func() {
+			err := runtimeError("var a = 1 + (2 * (3 - \"x\"));")
+			Expect(err.Token.Lexeme).To(Equal("-"))
+			Expect(err.Error()).To(Equal("1:21: operands must be a number for subtract operation"))
+		})
+
+		It("records the kiwi functions that were being called, innermost first", func() {
+			err := runtimeError("fn inner(x) {\n  return x / 0;\n}\nfn outer() {\n  return inner(1);\n}\nouter();")
+			Expect(err.Stack).To(HaveLen(2))
+			Expect(err.Stack[0].Function).To(Equal("inner"))
+			Expect(err.Stack[0].Call.Line).To(Equal(int64(5)))
+			Expect(err.Stack[1].Function).To(Equal("outer"))
+			Expect(err.Stack[1].Call.Line).To(Equal(int64(7)))
+			Expect(it.frames).To(BeEmpty())
+		})
+
+		It("prints the file along with every frame", func() {
+			it.SetFile("main.kiwi")
+			err := runtimeError("class Point {\n  init(x) {\n    this.x = -x;\n  }\n}\nPoint(\"a\");")
+			Expect(err.Error()).To(Equal("" +
+				"main.kiwi:3:14: operand must be a number\n" +
+				"\tin Point, called at main.kiwi:6:10"))
+		})
+
+		When("a function recurses without end", func() {
+			It("stops with a stack overflow instead of crashing", func() {
+				err := runtimeError("fn f(n) {\n  return f(n + 1);\n}\nf(0);")
+				Expect(err.Message).To(Equal("stack overflow, more than 10000 nested calls"))
+				Expect(err.Token.Line).To(Equal(int64(2)))
+				Expect(err.Stack).To(HaveLen(10000))
+				Expect(it.frames).To(BeEmpty())
+			})
+
+			It("only prints the innermost frames", func() {
+				err := runtimeError("fn f(n) {\n  return f(n + 1);\n}\nf(0);")
+				lines := strings.Split(err.Error(), "\n")
+				Expect(lines).To(HaveLen(12))
+				Expect(lines[1]).To(Equal("\tin f, called at 2:17"))
+				Expect(lines[11]).To(Equal("\t... 9990 more calls"))
+			})
+		})
+
+		It("leaves natives out of the stack", func() {
+			err := runtimeError("fn f(s) {\n  return upper(s);\n}\nf(1);")
+			Expect(err.Token.Line).To(Equal(int64(2)))
+			Expect(err.Stack).To(HaveLen(1))
+			Expect(err.Stack[0].Function).To(Equal("f"))
+		})
+
+		It("renders as a diagnostic with the frames as notes", func() {
+			list := diagnostics.From(runtimeError("fn f() {\n  return missing;\n}\nf();"))
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].Message).To(Equal("undefined variable: 'missing'"))
+			Expect(list[0].Notes).To(Equal([]string{"in f, called at line 4, column 3"}))
+		})
+	})
 })
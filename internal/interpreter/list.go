@@ -0,0 +1,80 @@
+package interpreter
+
+import (
+	"slices"
+	"strings"
+)
+
+// The runtime representation of a list literal. It is shared by reference, so
+// a list passed into a function can be modified by that function.
+type List struct {
+	elements []any
+}
+
+func NewList(elements []any) *List {
+	return &List{elements: elements}
+}
+
+func (l *List) Len() int {
+	return len(l.elements)
+}
+
+func (l *List) Get(index any) (any, error) {
+	i, err := position(index, len(l.elements), "list")
+	if err != nil {
+		return nil, err
+	}
+	return l.elements[i], nil
+}
+
+func (l *List) Set(index any, value any) error {
+	i, err := position(index, len(l.elements), "list")
+	if err != nil {
+		return err
+	}
+	l.elements[i] = value
+	return nil
+}
+
+// Copies out a part of the list, so changing the slice leaves the original alone
+func (l *List) Slice(start any, end any) (*List, error) {
+	from, to, err := sliceBounds(start, end, len(l.elements))
+	if err != nil {
+		return nil, err
+	}
+	return NewList(slices.Clone(l.elements[from:to])), nil
+}
+
+// Two lists are equal when they hold equal elements in the same order
+func (l *List) Equals(other *List) bool {
+	pair := [2]*List{l, other}
+	if l == other || comparing[pair] {
+		return true
+	}
+	if len(l.elements) != len(other.elements) {
+		return false
+	}
+	comparing[pair] = true
+	defer delete(comparing, pair)
+
+	for i := range l.elements {
+		if !isEqual(l.elements[i], other.elements[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *List) String() string {
+	if printing[l] {
+		return "[...]"
+	}
+	printing[l] = true
+	defer delete(printing, l)
+
+	parts := make([]string, len(l.elements))
+	for i, element := range l.elements {
+		parts[i] = repr(element)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,121 @@
+package interpreter
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+)
+
+type mapEntry struct {
+	key   any
+	value any
+}
+
+// The runtime representation of a map literal. Entries are kept in a linked list
+// alongside the lookup table, so iterating and printing follow insertion order
+// while lookups, inserts and deletes all stay O(1).
+type Map struct {
+	entries map[any]*list.Element
+	order   *list.List
+}
+
+func NewMap() *Map {
+	return &Map{
+		entries: make(map[any]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Only values that compare by value make sense as keys. Floats are left out on
+// purpose so that `m[1]` and `m[1.0]` can't silently refer to different entries.
+func checkKey(key any) error {
+	switch key.(type) {
+	case nil, bool, int, string:
+		return nil
+	case float64:
+		return fmt.Errorf("map keys must be a string, int, bool or nil, got float")
+	default:
+		return fmt.Errorf("map keys must be a string, int, bool or nil, got %s", typeName(key))
+	}
+}
+
+func (m *Map) Len() int {
+	return len(m.entries)
+}
+
+func (m *Map) Get(key any) (any, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in map", repr(key))
+	}
+	return element.Value.(*mapEntry).value, nil
+}
+
+// Overwriting an existing key keeps its original position
+func (m *Map) Set(key any, value any) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*mapEntry).value = value
+		return nil
+	}
+	m.entries[key] = m.order.PushBack(&mapEntry{key: key, value: value})
+	return nil
+}
+
+func (m *Map) Has(key any) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+	_, ok := m.entries[key]
+	return ok, nil
+}
+
+// Reports whether the key was there to be removed
+func (m *Map) Delete(key any) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+	element, ok := m.entries[key]
+	if !ok {
+		return false, nil
+	}
+	m.order.Remove(element)
+	delete(m.entries, key)
+	return true, nil
+}
+
+func (m *Map) Keys() []any {
+	keys := make([]any, 0, m.Len())
+	for e := m.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*mapEntry).key)
+	}
+	return keys
+}
+
+func (m *Map) Values() []any {
+	values := make([]any, 0, m.Len())
+	for e := m.order.Front(); e != nil; e = e.Next() {
+		values = append(values, e.Value.(*mapEntry).value)
+	}
+	return values
+}
+
+func (m *Map) String() string {
+	if printing[m] {
+		return "{...}"
+	}
+	printing[m] = true
+	defer delete(printing, m)
+
+	parts := make([]string, 0, m.Len())
+	for e := m.order.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*mapEntry)
+		parts = append(parts, repr(entry.key)+": "+repr(entry.value))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
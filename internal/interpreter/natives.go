@@ -0,0 +1,120 @@
+package interpreter
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/maxcelant/kiwi/internal/env"
+)
+
+// A function implemented in Go rather than in kiwi. These are defined in the
+// global scope before a program runs, so they can be shadowed like any other name.
+type NativeFunction struct {
+	name  string
+	arity int
+	fn    func(it *Interpreter, args []any) (any, error)
+}
+
+func (n *NativeFunction) Arity() int {
+	return n.arity
+}
+
+func (n *NativeFunction) Call(it *Interpreter, args []any) (any, error) {
+	return n.fn(it, args)
+}
+
+func (n *NativeFunction) String() string {
+	return fmt.Sprintf("<native fn %s>", n.name)
+}
+
+// Every native function, keyed by the global name it is defined under. Each
+// runtime type registers its own natives from an init function in its file.
+var registry = map[string]*NativeFunction{}
+
+func register(name string, arity int, fn func(it *Interpreter, args []any) (any, error)) {
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("native function %s registered twice", name))
+	}
+	registry[name] = &NativeFunction{name: name, arity: arity, fn: fn}
+}
+
+func defineNatives(environment *env.Environment) {
+	for name, native := range registry {
+		environment.Define(name, native)
+	}
+}
+
+func init() {
+	register("len", 1, nativeLen)
+	register("keys", 1, nativeKeys)
+	register("values", 1, nativeValues)
+	register("has", 2, nativeHas)
+	register("delete", 2, nativeDelete)
+}
+
+func nativeLen(it *Interpreter, args []any) (any, error) {
+	switch v := args[0].(type) {
+	case string:
+		return utf8.RuneCountInString(v), nil
+	case *List:
+		return v.Len(), nil
+	case *Map:
+		return v.Len(), nil
+	case *Deque:
+		return v.Len(), nil
+	case *Heap:
+		return v.Len(), nil
+	case *Tree:
+		return v.Len(), nil
+	default:
+		return nil, fmt.Errorf("len expects a string, list, map, deque, heap or tree but got %s", Stringify(v))
+	}
+}
+
+// Implemented by both hash maps and tree maps, so the map builtins work on either
+type keyed interface {
+	Has(key any) (bool, error)
+	Delete(key any) (bool, error)
+	Keys() []any
+	Values() []any
+}
+
+func mapArgument(name string, arg any) (keyed, error) {
+	m, ok := arg.(keyed)
+	if !ok {
+		return nil, fmt.Errorf("%s expects a map but got %s", name, Stringify(arg))
+	}
+	return m, nil
+}
+
+func nativeKeys(it *Interpreter, args []any) (any, error) {
+	m, err := mapArgument("keys", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return NewList(m.Keys()), nil
+}
+
+func nativeValues(it *Interpreter, args []any) (any, error) {
+	m, err := mapArgument("values", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return NewList(m.Values()), nil
+}
+
+func nativeHas(it *Interpreter, args []any) (any, error) {
+	m, err := mapArgument("has", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return m.Has(args[1])
+}
+
+func nativeDelete(it *Interpreter, args []any) (any, error) {
+	m, err := mapArgument("delete", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return m.Delete(args[1])
+}
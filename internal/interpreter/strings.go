@@ -0,0 +1,135 @@
+package interpreter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Strings are indexed and measured by rune rather than by byte, so `"héllo"[1]`
+// is "é" and `len("héllo")` is 5
+
+func stringIndex(s string, index any) (any, error) {
+	runes := []rune(s)
+	i, err := position(index, len(runes), "string")
+	if err != nil {
+		return nil, err
+	}
+	return string(runes[i]), nil
+}
+
+func stringSlice(s string, start any, end any) (any, error) {
+	runes := []rune(s)
+	from, to, err := sliceBounds(start, end, len(runes))
+	if err != nil {
+		return nil, err
+	}
+	return string(runes[from:to]), nil
+}
+
+func stringArgument(name string, arg any) (string, error) {
+	s, ok := arg.(string)
+	if !ok {
+		return "", fmt.Errorf("%s expects a string but got %s", name, Stringify(arg))
+	}
+	return s, nil
+}
+
+// Registers a helper that takes a single string, such as upper or trim
+func registerString(name string, fn func(s string) any) {
+	register(name, 1, func(it *Interpreter, args []any) (any, error) {
+		s, err := stringArgument(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(s), nil
+	})
+}
+
+// Registers a helper that takes two strings, such as startsWith or indexOf
+func registerStrings(name string, fn func(s string, t string) any) {
+	register(name, 2, func(it *Interpreter, args []any) (any, error) {
+		s, err := stringArgument(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		t, err := stringArgument(name, args[1])
+		if err != nil {
+			return nil, err
+		}
+		return fn(s, t), nil
+	})
+}
+
+func init() {
+	registerString("trim", func(s string) any { return strings.TrimSpace(s) })
+	registerString("upper", func(s string) any { return strings.ToUpper(s) })
+	registerString("lower", func(s string) any { return strings.ToLower(s) })
+	registerString("reverse", func(s string) any {
+		runes := []rune(s)
+		slices.Reverse(runes)
+		return string(runes)
+	})
+	registerStrings("startsWith", func(s string, prefix string) any { return strings.HasPrefix(s, prefix) })
+	registerStrings("endsWith", func(s string, suffix string) any { return strings.HasSuffix(s, suffix) })
+	// Returns the rune index of the first match, or -1 when there isn't one
+	registerStrings("indexOf", func(s string, sub string) any {
+		i := strings.Index(s, sub)
+		if i < 0 {
+			return -1
+		}
+		return utf8.RuneCountInString(s[:i])
+	})
+	// An empty separator splits the string into its individual characters
+	registerStrings("split", func(s string, sep string) any {
+		parts := strings.Split(s, sep)
+		elements := make([]any, len(parts))
+		for i, part := range parts {
+			elements[i] = part
+		}
+		return NewList(elements)
+	})
+	register("replace", 3, func(it *Interpreter, args []any) (any, error) {
+		s, err := stringArgument("replace", args[0])
+		if err != nil {
+			return nil, err
+		}
+		old, err := stringArgument("replace", args[1])
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := stringArgument("replace", args[2])
+		if err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(s, old, replacement), nil
+	})
+	// Anything that isn't a string is joined the same way print would show it
+	register("join", 2, func(it *Interpreter, args []any) (any, error) {
+		list, ok := args[0].(*List)
+		if !ok {
+			return nil, fmt.Errorf("join expects a list but got %s", Stringify(args[0]))
+		}
+		sep, err := stringArgument("join", args[1])
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, len(list.elements))
+		for i, element := range list.elements {
+			parts[i] = Stringify(element)
+		}
+		return strings.Join(parts, sep), nil
+	})
+	register("repeat", 2, func(it *Interpreter, args []any) (any, error) {
+		s, err := stringArgument("repeat", args[0])
+		if err != nil {
+			return nil, err
+		}
+		count, ok := args[1].(int)
+		if !ok || count < 0 {
+			return nil, fmt.Errorf("repeat expects a non-negative integer count but got %s", Stringify(args[1]))
+		}
+		return strings.Repeat(s, count), nil
+	})
+}
@@ -0,0 +1,489 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type treeNode struct {
+	key    any
+	value  any
+	left   *treeNode
+	right  *treeNode
+	height int
+	size   int // Number of nodes in this subtree, which is what makes rank queries O(log n)
+}
+
+func height(n *treeNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func size(n *treeNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *treeNode) update() {
+	n.height = 1 + max(height(n.left), height(n.right))
+	n.size = 1 + size(n.left) + size(n.right)
+}
+
+func rotateRight(n *treeNode) *treeNode {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func rotateLeft(n *treeNode) *treeNode {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+// Restores the AVL invariant, where the heights of two siblings differ by at
+// most one, after an insert or delete somewhere below n
+func rebalance(n *treeNode) *treeNode {
+	n.update()
+	balance := height(n.left) - height(n.right)
+	if balance > 1 {
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	}
+	if balance < -1 {
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// An ordered map backed by an AVL tree. Keys are ordered with compareValues, the
+// same as the comparison operators, so any mix of numbers, strings or lists that
+// `<` accepts can be used. A tree set is the same structure with no values.
+type Tree struct {
+	root  *treeNode
+	isSet bool
+}
+
+func NewTree(isSet bool) *Tree {
+	return &Tree{isSet: isSet}
+}
+
+func (t *Tree) Len() int {
+	return size(t.root)
+}
+
+func (t *Tree) find(key any) (*treeNode, error) {
+	if err := orderable(key); err != nil {
+		return nil, err
+	}
+	n := t.root
+	for n != nil {
+		c, err := compareValues(key, n.key)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return n, nil
+		}
+		if c < 0 {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return nil, nil
+}
+
+func (t *Tree) Get(key any) (any, error) {
+	if t.isSet {
+		return nil, fmt.Errorf("tree sets can't be indexed, use has instead")
+	}
+	n, err := t.find(key)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, fmt.Errorf("key %s not found in tree map", repr(key))
+	}
+	return n.value, nil
+}
+
+func (t *Tree) Set(key any, value any) error {
+	if t.isSet {
+		return fmt.Errorf("tree sets can't be assigned to, use treeAdd instead")
+	}
+	return t.put(key, value)
+}
+
+// An empty tree never compares the first key, so keys are checked up front.
+// Otherwise a nil or bool key would get in and break every insert after it.
+func (t *Tree) put(key any, value any) error {
+	if err := orderable(key); err != nil {
+		return err
+	}
+	root, err := t.insert(t.root, key, value)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+func (t *Tree) insert(n *treeNode, key any, value any) (*treeNode, error) {
+	if n == nil {
+		return &treeNode{key: key, value: value, height: 1, size: 1}, nil
+	}
+	c, err := compareValues(key, n.key)
+	if err != nil {
+		return nil, err
+	}
+	if c == 0 {
+		n.value = value
+		return n, nil
+	}
+	// Only link the child back in once it's known to be good, a failed comparison
+	// further down must leave this subtree as it was
+	if c < 0 {
+		left, err := t.insert(n.left, key, value)
+		if err != nil {
+			return nil, err
+		}
+		n.left = left
+	} else {
+		right, err := t.insert(n.right, key, value)
+		if err != nil {
+			return nil, err
+		}
+		n.right = right
+	}
+	return rebalance(n), nil
+}
+
+func (t *Tree) Has(key any) (bool, error) {
+	n, err := t.find(key)
+	return n != nil, err
+}
+
+// Reports whether the key was there to be removed
+func (t *Tree) Delete(key any) (bool, error) {
+	found, err := t.Has(key)
+	if err != nil || !found {
+		return false, err
+	}
+	root, err := t.remove(t.root, key)
+	if err != nil {
+		return false, err
+	}
+	t.root = root
+	return true, nil
+}
+
+func (t *Tree) remove(n *treeNode, key any) (*treeNode, error) {
+	c, err := compareValues(key, n.key)
+	if err != nil {
+		return nil, err
+	}
+	if c < 0 {
+		left, err := t.remove(n.left, key)
+		if err != nil {
+			return nil, err
+		}
+		n.left = left
+	} else if c > 0 {
+		right, err := t.remove(n.right, key)
+		if err != nil {
+			return nil, err
+		}
+		n.right = right
+	} else {
+		if n.left == nil {
+			return n.right, nil
+		}
+		if n.right == nil {
+			return n.left, nil
+		}
+		// Replace the node with its successor, then remove the successor from the right
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		right, err := t.remove(n.right, successor.key)
+		if err != nil {
+			return nil, err
+		}
+		n.key, n.value = successor.key, successor.value
+		n.right = right
+	}
+	return rebalance(n), nil
+}
+
+// The smallest key, or nil when the tree is empty
+func (t *Tree) Min() any {
+	n := t.root
+	if n == nil {
+		return nil
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.key
+}
+
+// The largest key, or nil when the tree is empty
+func (t *Tree) Max() any {
+	n := t.root
+	if n == nil {
+		return nil
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.key
+}
+
+// The largest key less than or equal to key, or nil if there isn't one
+func (t *Tree) Floor(key any) (any, error) {
+	if err := orderable(key); err != nil {
+		return nil, err
+	}
+	var result any
+	n := t.root
+	for n != nil {
+		c, err := compareValues(key, n.key)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return n.key, nil
+		}
+		if c < 0 {
+			n = n.left
+		} else {
+			result = n.key
+			n = n.right
+		}
+	}
+	return result, nil
+}
+
+// The smallest key greater than or equal to key, or nil if there isn't one
+func (t *Tree) Ceiling(key any) (any, error) {
+	if err := orderable(key); err != nil {
+		return nil, err
+	}
+	var result any
+	n := t.root
+	for n != nil {
+		c, err := compareValues(key, n.key)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return n.key, nil
+		}
+		if c > 0 {
+			n = n.right
+		} else {
+			result = n.key
+			n = n.left
+		}
+	}
+	return result, nil
+}
+
+// The number of keys strictly less than key, whether or not key is in the tree
+func (t *Tree) Rank(key any) (int, error) {
+	if err := orderable(key); err != nil {
+		return 0, err
+	}
+	rank := 0
+	n := t.root
+	for n != nil {
+		c, err := compareValues(key, n.key)
+		if err != nil {
+			return 0, err
+		}
+		if c <= 0 {
+			n = n.left
+		} else {
+			rank += size(n.left) + 1
+			n = n.right
+		}
+	}
+	return rank, nil
+}
+
+// The keys between low and high, both inclusive, in order. Subtrees that are
+// entirely outside of the range are skipped.
+func (t *Tree) Range(low any, high any) ([]any, error) {
+	for _, bound := range []any{low, high} {
+		if err := orderable(bound); err != nil {
+			return nil, err
+		}
+	}
+	keys := []any{}
+	var walk func(n *treeNode) error
+	walk = func(n *treeNode) error {
+		if n == nil {
+			return nil
+		}
+		lc, err := compareValues(n.key, low)
+		if err != nil {
+			return err
+		}
+		hc, err := compareValues(n.key, high)
+		if err != nil {
+			return err
+		}
+		if lc > 0 {
+			if err := walk(n.left); err != nil {
+				return err
+			}
+		}
+		if lc >= 0 && hc <= 0 {
+			keys = append(keys, n.key)
+		}
+		if hc < 0 {
+			return walk(n.right)
+		}
+		return nil
+	}
+	return keys, walk(t.root)
+}
+
+func (t *Tree) each(fn func(n *treeNode)) {
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n == nil {
+			return
+		}
+		walk(n.left)
+		fn(n)
+		walk(n.right)
+	}
+	walk(t.root)
+}
+
+func (t *Tree) Keys() []any {
+	keys := make([]any, 0, t.Len())
+	t.each(func(n *treeNode) { keys = append(keys, n.key) })
+	return keys
+}
+
+func (t *Tree) Values() []any {
+	values := make([]any, 0, t.Len())
+	t.each(func(n *treeNode) { values = append(values, n.value) })
+	return values
+}
+
+func (t *Tree) String() string {
+	if printing[t] {
+		if t.isSet {
+			return "treeSet{...}"
+		}
+		return "treeMap{...}"
+	}
+	printing[t] = true
+	defer delete(printing, t)
+
+	parts := make([]string, 0, t.Len())
+	t.each(func(n *treeNode) {
+		if t.isSet {
+			parts = append(parts, repr(n.key))
+		} else {
+			parts = append(parts, repr(n.key)+": "+repr(n.value))
+		}
+	})
+	if t.isSet {
+		return "treeSet{" + strings.Join(parts, ", ") + "}"
+	}
+	return "treeMap{" + strings.Join(parts, ", ") + "}"
+}
+
+func init() {
+	register("treeMap", 0, func(it *Interpreter, args []any) (any, error) {
+		return NewTree(false), nil
+	})
+	register("treeSet", 0, func(it *Interpreter, args []any) (any, error) {
+		return NewTree(true), nil
+	})
+	register("treeAdd", 2, func(it *Interpreter, args []any) (any, error) {
+		t, err := treeArgument("treeAdd", args[0])
+		if err != nil {
+			return nil, err
+		}
+		if !t.isSet {
+			return nil, fmt.Errorf("treeAdd expects a tree set, use indexing to assign to a tree map")
+		}
+		return nil, t.put(args[1], nil)
+	})
+	register("treeMin", 1, func(it *Interpreter, args []any) (any, error) {
+		t, err := treeArgument("treeMin", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.Min(), nil
+	})
+	register("treeMax", 1, func(it *Interpreter, args []any) (any, error) {
+		t, err := treeArgument("treeMax", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.Max(), nil
+	})
+	register("treeFloor", 2, func(it *Interpreter, args []any) (any, error) {
+		t, err := treeArgument("treeFloor", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.Floor(args[1])
+	})
+	register("treeCeiling", 2, func(it *Interpreter, args []any) (any, error) {
+		t, err := treeArgument("treeCeiling", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.Ceiling(args[1])
+	})
+	register("treeRank", 2, func(it *Interpreter, args []any) (any, error) {
+		t, err := treeArgument("treeRank", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.Rank(args[1])
+	})
+	register("treeRange", 3, func(it *Interpreter, args []any) (any, error) {
+		t, err := treeArgument("treeRange", args[0])
+		if err != nil {
+			return nil, err
+		}
+		keys, err := t.Range(args[1], args[2])
+		if err != nil {
+			return nil, err
+		}
+		return NewList(keys), nil
+	})
+}
+
+func treeArgument(name string, arg any) (*Tree, error) {
+	t, ok := arg.(*Tree)
+	if !ok {
+		return nil, fmt.Errorf("%s expects a tree map or tree set but got %s", name, Stringify(arg))
+	}
+	return t, nil
+}
@@ -1,23 +1,33 @@
 package lexer
 
-import "strconv"
+import (
+	"strconv"
+	"unicode"
+)
 
-func isAlphaNumeric(b byte) bool {
-	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9')
+// Identifiers can start with an underscore or any Unicode letter, so `_tmp`,
+// `größe` and `变量` are all valid names
+func isAlpha(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
 }
 
-func isAlpha(b byte) bool {
-	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')
+func isAlphaNumeric(r rune) bool {
+	return isAlpha(r) || unicode.IsDigit(r)
 }
 
-func isNumber(b byte) bool {
-	return (b >= '0' && b <= '9')
+// Number literals are still ASCII digits only
+func isNumber(r rune) bool {
+	return (r >= '0' && r <= '9')
 }
 
-func String(ch byte) string {
+func String(ch rune) string {
 	return string(ch)
 }
 
 func Number(s string) (int, error) {
 	return strconv.Atoi(s)
 }
+
+func Float(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
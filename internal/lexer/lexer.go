@@ -1,38 +1,57 @@
 package lexer
 
 import (
-	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxcelant/kiwi/internal/diagnostics"
 )
 
+// Line and column always point at the next rune to be read. startLine and
+// startColumn are where the token being scanned began, which is what the token
+// reports, so a multi-line string is placed on the line it opened on.
 type Lexer struct {
-	Line   int64
-	start  int64
-	curr   int64
-	tokens []Token
-	source string
+	Line        int64
+	column      int64
+	startLine   int64
+	startColumn int64
+	start       int64
+	curr        int64
+	tokens      []Token
+	source      string
+	// One entry per `${` that hasn't been closed yet, counting the braces opened
+	// inside of it, so we know which `}` goes back to scanning the string
+	interpolations []int
 }
 
 var keywords = map[string]TokenType{
-	"if":     IF,
-	"else":   ELSE,
-	"or":     OR,
-	"and":    AND,
-	"for":    FOR,
-	"while":  WHILE,
-	"return": RETURN,
-	"fn":     FUNC,
-	"class":  CLASS,
-	"var":    VAR,
-	"print":  PRINT,
-	"true":   TRUE,
-	"false":  FALSE,
-	"nil":    NIL,
+	"if":       IF,
+	"else":     ELSE,
+	"or":       OR,
+	"and":      AND,
+	"for":      FOR,
+	"while":    WHILE,
+	"return":   RETURN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"fn":       FUNC,
+	"class":    CLASS,
+	"this":     THIS,
+	"super":    SUPER,
+	"var":      VAR,
+	"print":    PRINT,
+	"true":     TRUE,
+	"false":    FALSE,
+	"nil":      NIL,
 }
 
 func New(source string) *Lexer {
 	return &Lexer{
 		source: source,
-		Line:   0,
+		Line:   1,
+		column: 1,
 		start:  0,
 		curr:   0,
 		tokens: []Token{},
@@ -40,25 +59,45 @@ func New(source string) *Lexer {
 }
 
 func (l *Lexer) Scan() ([]Token, error) {
+	if l.Line == 0 {
+		l.Line, l.column = 1, 1
+	}
 	for !l.atEnd() {
-		l.start = l.curr
+		l.startToken()
 		err := l.scanToken()
 		if err != nil {
 			return nil, err
 		}
 	}
-	l.tokens = append(l.tokens, Token{Type: EOF, Lexeme: "", Literal: nil, Line: l.Line})
+	if len(l.interpolations) > 0 {
+		return nil, l.error("unterminated string interpolation")
+	}
+	l.startToken()
+	l.tokens = append(l.tokens, Token{
+		Type:    EOF,
+		Lexeme:  "",
+		Literal: nil,
+		Line:    l.Line,
+		Column:  l.column,
+		Start:   l.curr,
+		End:     l.curr,
+	})
 	return l.tokens, nil
 }
 
+// Scans one line of input at a time. Each call is the next line, so the first
+// call is line 1, and offsets are relative to the start of the line.
 func (l *Lexer) ScanLine(source string) ([]Token, error) {
-	l.Line += 1
+	if l.Line == 0 {
+		l.Line = 1
+	}
+	l.column = 1
 	l.source = source
 	l.curr = 0
 	l.tokens = []Token{}
 
 	for {
-		l.start = l.curr
+		l.startToken()
 		if l.curr >= int64(len(source)) {
 			break
 		}
@@ -68,33 +107,74 @@ func (l *Lexer) ScanLine(source string) ([]Token, error) {
 		}
 	}
 
+	l.Line += 1
 	return l.tokens, nil
 }
 
+func (l *Lexer) startToken() {
+	l.start = l.curr
+	l.startLine = l.Line
+	l.startColumn = l.column
+}
+
 func (l *Lexer) scanToken() error {
 	var err error
 	ch := l.advance()
 
-	if ch == ' ' || ch == '\r' || ch == '\t' {
+	if ch == ' ' || ch == '\r' || ch == '\t' || ch == '\n' {
 		return nil
-	} else if ch == '\n' {
-		l.Line += 1
 	} else if ch == ';' {
 		l.addToken(SEMICOLON)
 	} else if ch == '{' {
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1] += 1
+		}
 		l.addToken(LEFT_BRACE)
 	} else if ch == '}' {
+		n := len(l.interpolations)
+		if n > 0 && l.interpolations[n-1] == 0 {
+			// This closes a `${`, so the rest of the string picks up from here
+			l.interpolations = l.interpolations[:n-1]
+			return l.handleString()
+		}
+		if n > 0 {
+			l.interpolations[n-1] -= 1
+		}
 		l.addToken(RIGHT_BRACE)
+	} else if ch == ':' {
+		l.addToken(COLON)
+	} else if ch == '[' {
+		l.addToken(LEFT_BRACKET)
+	} else if ch == ']' {
+		l.addToken(RIGHT_BRACKET)
 	} else if ch == '(' {
 		l.addToken(LEFT_PAREN)
 	} else if ch == ')' {
 		l.addToken(RIGHT_PAREN)
+	} else if ch == ',' {
+		l.addToken(COMMA)
+	} else if ch == '.' {
+		l.addToken(DOT)
 	} else if ch == '+' {
 		l.addToken(PLUS)
 	} else if ch == '-' {
 		l.addToken(MINUS)
 	} else if ch == '*' {
-		l.addToken(STAR)
+		next := l.match('*')
+		if next {
+			l.addToken(STAR_STAR)
+		} else {
+			l.addToken(STAR)
+		}
+	} else if ch == '%' {
+		l.addToken(PERCENT)
+	} else if ch == '~' {
+		// Floor division can't be spelled `//` since that already starts a comment
+		next := l.match('/')
+		if !next {
+			return l.error("expect '/' after '~'")
+		}
+		l.addToken(TILDE_SLASH)
 	} else if ch == '!' {
 		next := l.match('=')
 		if next {
@@ -129,9 +209,6 @@ func (l *Lexer) scanToken() error {
 			for !l.atEnd() && l.peek() != '\n' {
 				l.advance()
 			}
-			if l.peek() == '\n' {
-				l.advance()
-			}
 			return nil
 		} else {
 			l.addToken(SLASH)
@@ -141,23 +218,41 @@ func (l *Lexer) scanToken() error {
 		if err != nil {
 			return err
 		}
+	} else if ch == '`' {
+		if err = l.handleRawString(); err != nil {
+			return err
+		}
 	} else if isNumber(ch) {
 		if err = l.handleNumber(); err != nil {
 			return err
 		}
 	} else if isAlpha(ch) {
 		l.handleIdentifier()
+	} else if ch == utf8.RuneError {
+		return l.error("invalid UTF-8 encoding")
+	} else {
+		return l.error(fmt.Sprintf("unexpected character '%c'", ch))
 	}
 	return nil
 }
 
+// Points an error at the token being scanned, from where it started up to
+// wherever the lexer gave up on it
+func (l *Lexer) error(message string) *diagnostics.Diagnostic {
+	span := diagnostics.Span{Line: l.startLine, Column: l.startColumn, Start: l.start, End: l.curr}
+	return diagnostics.New(diagnostics.Error, span, message)
+}
+
 func (l *Lexer) addToken(tokenType TokenType) {
 	ch := l.source[l.start:l.curr]
 	token := Token{
 		Type:    tokenType,
 		Literal: ch,
 		Lexeme:  ch,
-		Line:    l.Line,
+		Line:    l.startLine,
+		Column:  l.startColumn,
+		Start:   l.start,
+		End:     l.curr,
 	}
 	l.tokens = append(l.tokens, token)
 }
@@ -168,20 +263,17 @@ func (l *Lexer) addTokenWithLiteral(tokenType TokenType, literal any) {
 		Type:    tokenType,
 		Literal: literal,
 		Lexeme:  ch,
-		Line:    l.Line,
+		Line:    l.startLine,
+		Column:  l.startColumn,
+		Start:   l.start,
+		End:     l.curr,
 	}
 	l.tokens = append(l.tokens, token)
 }
 
 func (l *Lexer) handleIdentifier() {
-	for {
-		next := l.peek()
-		if next == 0 || next == ' ' || !isAlphaNumeric(next) {
-			break
-		}
-		if isAlpha(next) {
-			l.advance()
-		}
+	for isAlphaNumeric(l.peek()) {
+		l.advance()
 	}
 	k := l.source[l.start:l.curr]
 	tokenType, ok := keywords[k]
@@ -192,64 +284,176 @@ func (l *Lexer) handleIdentifier() {
 }
 
 func (l *Lexer) handleNumber() error {
-	for {
-		next := l.peek()
-		if isAlpha(next) {
-			return errors.New("invalid number: contains alphabetic characters")
-		}
-		if next == 0 || next == ' ' || !isNumber(next) {
-			break
-		}
-		if isNumber(next) {
-			l.advance()
+	l.consumeDigits()
+
+	// A '.' only starts a fraction when a digit follows it, so `1.foo` is still
+	// lexed as a number followed by a property access
+	isFloat := false
+	if l.peek() == '.' && isNumber(l.peekNext()) {
+		isFloat = true
+		l.advance()
+		l.consumeDigits()
+	}
+	if isAlpha(l.peek()) {
+		return l.error("invalid number: contains alphabetic characters")
+	}
+
+	if isFloat {
+		literal, err := Float(l.source[l.start:l.curr])
+		if err != nil {
+			return l.error(fmt.Sprintf("invalid number: %s", err))
 		}
+		l.addTokenWithLiteral(NUMBER, literal)
+		return nil
+	}
+	literal, err := Number(l.source[l.start:l.curr])
+	if err != nil {
+		return l.error(fmt.Sprintf("invalid number: %s", err))
 	}
-	literal, _ := Number(l.source[l.start:l.curr]) // todo: handle error
 	l.addTokenWithLiteral(NUMBER, literal)
 	return nil
 }
 
+func (l *Lexer) consumeDigits() {
+	for isNumber(l.peek()) {
+		l.advance()
+	}
+}
+
+// Scans up to the closing quote, or up to the next `${`. An interpolated string
+// such as `"a ${b} c"` becomes an INTERPOLATION token for "a ", the tokens of the
+// expression, and then a STRING token for " c" once the matching `}` is reached.
 func (l *Lexer) handleString() error {
-	for {
-		next := l.peek()
-		if next == 0 || next == '"' {
-			break
+	var value strings.Builder
+	for !l.atEnd() && l.peek() != '"' {
+		if l.peek() == '$' && l.peekNext() == '{' {
+			l.advance()
+			l.advance()
+			l.interpolations = append(l.interpolations, 0)
+			l.addTokenWithLiteral(INTERPOLATION, value.String())
+			return nil
 		}
-		if next == '\n' {
-			l.Line += 1
+		ch := l.advance()
+		if ch == '\\' {
+			if err := l.handleEscape(&value); err != nil {
+				return err
+			}
+			continue
 		}
-		l.advance()
+		value.WriteRune(ch)
 	}
 	if l.atEnd() {
-		return errors.New("unterminated string")
+		return l.error("unterminated string")
 	}
 	l.advance() // Skips the closing `"`
-	l.addTokenWithLiteral(STRING, l.source[l.start+1:l.curr-1])
+	l.addTokenWithLiteral(STRING, value.String())
 	return nil
 }
 
-func (l *Lexer) advance() byte {
-	c := l.source[l.curr]
-	l.curr += 1
-	return c
+// Writes the character that an escape sequence stands for, once its `\` has been consumed
+func (l *Lexer) handleEscape(value *strings.Builder) error {
+	if l.atEnd() {
+		return l.error("unterminated string")
+	}
+	ch := l.advance()
+	switch ch {
+	case 'n':
+		value.WriteByte('\n')
+	case 't':
+		value.WriteByte('\t')
+	case '\\':
+		value.WriteByte('\\')
+	case '"':
+		value.WriteByte('"')
+	case '$':
+		value.WriteByte('$')
+	case 'u':
+		r, err := l.handleUnicodeEscape()
+		if err != nil {
+			return err
+		}
+		value.WriteRune(r)
+	default:
+		return l.error(fmt.Sprintf("unknown escape sequence '\\%c' in string", ch))
+	}
+	return nil
 }
 
-func (l *Lexer) match(next byte) (matches bool) {
+// Reads the `{...}` part of a `\u{...}` escape, which holds 1 to 6 hex digits
+func (l *Lexer) handleUnicodeEscape() (rune, error) {
+	if !l.match('{') {
+		return 0, l.error("expect '{' after '\\u' in string")
+	}
+	start := l.curr
+	for !l.atEnd() && l.peek() != '}' && l.peek() != '"' {
+		l.advance()
+	}
+	if !l.match('}') {
+		return 0, l.error("expect '}' to close unicode escape in string")
+	}
+	digits := l.source[start : l.curr-1]
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+		return 0, l.error(fmt.Sprintf("invalid unicode escape '\\u{%s}' in string", digits))
+	}
+	return rune(code), nil
+}
+
+// Backtick strings are raw: escapes are left as they are and they can span
+// multiple lines, which is handy for embedding test input
+func (l *Lexer) handleRawString() error {
+	for !l.atEnd() && l.peek() != '`' {
+		l.advance()
+	}
 	if l.atEnd() {
-		return false
+		return l.error("unterminated raw string")
+	}
+	l.advance() // Skips the closing '`'
+	l.addTokenWithLiteral(STRING, l.source[l.start+1:l.curr-1])
+	return nil
+}
+
+// The lexer walks the source one rune at a time, while start and curr stay byte
+// offsets so that lexemes can still be sliced straight out of the source. This
+// is the only place that moves forward, so it's also where lines are counted.
+func (l *Lexer) advance() rune {
+	r, size := utf8.DecodeRuneInString(l.source[l.curr:])
+	l.curr += int64(size)
+	if r == '\n' {
+		l.Line += 1
+		l.column = 1
+	} else {
+		l.column += 1
 	}
-	if l.source[l.curr] != next {
+	return r
+}
+
+func (l *Lexer) match(next rune) (matches bool) {
+	if l.atEnd() || l.peek() != next {
 		return false
 	}
-	l.curr += 1
+	l.advance()
 	return true
 }
 
-func (l *Lexer) peek() (next byte) {
+func (l *Lexer) peek() (next rune) {
 	if l.atEnd() {
 		return 0
 	}
-	return l.source[l.curr]
+	r, _ := utf8.DecodeRuneInString(l.source[l.curr:])
+	return r
+}
+
+func (l *Lexer) peekNext() (next rune) {
+	if l.atEnd() {
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(l.source[l.curr:])
+	if l.curr+int64(size) >= int64(len(l.source)) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.source[l.curr+int64(size):])
+	return r
 }
 
 func (l *Lexer) atEnd() bool {
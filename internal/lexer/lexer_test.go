@@ -1,7 +1,6 @@
 package lexer
 
 import (
-	"errors"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -54,6 +53,9 @@ var _ = Describe("Lexer", func() {
 				Literal: ";",
 				Lexeme:  ";",
 				Line:    1,
+				Column:  1,
+				Start:   0,
+				End:     1,
 			}
 			Expect(result).To(Equal([]Token{expected}))
 		})
@@ -67,6 +69,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "=",
 					Lexeme:  "=",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -100,6 +105,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "foobar",
 					Lexeme:  "\"foobar\"",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     8,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -114,6 +122,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "123",
 					Lexeme:  "\"123\"",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     5,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -128,6 +139,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "foobar123",
 					Lexeme:  "\"foobar123\"",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     11,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -142,11 +156,116 @@ var _ = Describe("Lexer", func() {
 					Literal: "foo bar",
 					Lexeme:  "\"foo bar\"",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     9,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
 		})
+		DescribeTable("its a string with escape sequences",
+			func(in string, literal string) {
+				result, err := lexer.ScanLine(in)
+				Expect(err).To(BeNil())
+				Expect(result).To(HaveLen(1))
+				Expect(result[0].Type).To(Equal(STRING))
+				Expect(result[0].Literal).To(Equal(literal))
+				Expect(result[0].Lexeme).To(Equal(in))
+			},
+			Entry("a newline", `"a\nb"`, "a\nb"),
+			Entry("a tab", `"a\tb"`, "a\tb"),
+			Entry("a backslash", `"a\\b"`, `a\b`),
+			Entry("an embedded quote", `"say \"hi\""`, `say "hi"`),
+			Entry("a unicode escape", `"caf\u{e9}"`, "café"),
+			Entry("a unicode escape outside of the basic plane", `"\u{1F95D}"`, "🥝"),
+		)
 
+		DescribeTable("its a string with an invalid escape sequence",
+			func(in string, message string) {
+				_, err := lexer.ScanLine(in)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("an unknown escape", `"a\qb"`, `unknown escape sequence '\q'`),
+			Entry("a unicode escape without braces", `"\u00e9"`, `expect '{' after '\u'`),
+			Entry("an unclosed unicode escape", `"\u{e9"`, "expect '}' to close unicode escape"),
+			Entry("a unicode escape that isn't hex", `"\u{zz}"`, `invalid unicode escape '\u{zz}'`),
+			Entry("a unicode escape past the last code point", `"\u{110000}"`, "invalid unicode escape"),
+			Entry("a backslash at the end of the input", `"a\`, "unterminated string"),
+		)
+
+		When("its a raw string", func() {
+			It("should leave backslashes alone", func() {
+				in := "`a\\nb`"
+				result, err := lexer.ScanLine(in)
+				Expect(err).To(BeNil())
+				expected := Token{
+					Type:    STRING,
+					Literal: `a\nb`,
+					Lexeme:  "`a\\nb`",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     6,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+
+			It("should span multiple lines and keep counting them", func() {
+				result, err := New("var s = `one\ntwo\nthree`;\nprint s;").Scan()
+				Expect(err).To(BeNil())
+				Expect(result[3].Type).To(Equal(STRING))
+				Expect(result[3].Literal).To(Equal("one\ntwo\nthree"))
+				Expect(result[5].Lexeme).To(Equal("print"))
+				Expect(result[5].Line).To(Equal(result[4].Line + 1))
+			})
+
+			It("should return an error when it is never closed", func() {
+				_, err := lexer.ScanLine("`abc")
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("unterminated raw string"))
+			})
+		})
+		When("its a string with an interpolated expression", func() {
+			It("should split it into an interpolation, the expression and the rest of the string", func() {
+				result, err := New(`"a ${b} c"`).Scan()
+				Expect(err).To(BeNil())
+				types := []TokenType{}
+				literals := []any{}
+				for _, token := range result {
+					types = append(types, token.Type)
+					literals = append(literals, token.Literal)
+				}
+				Expect(types).To(Equal([]TokenType{INTERPOLATION, IDENTIFIER, STRING, EOF}))
+				Expect(literals).To(Equal([]any{"a ", "b", " c", nil}))
+			})
+
+			It("should only resume the string on the brace that closes the interpolation", func() {
+				result, err := New(`"${ {"k": "${x}"} } end"`).Scan()
+				Expect(err).To(BeNil())
+				types := []TokenType{}
+				for _, token := range result {
+					types = append(types, token.Type)
+				}
+				Expect(types).To(Equal([]TokenType{
+					INTERPOLATION, LEFT_BRACE, STRING, COLON, INTERPOLATION, IDENTIFIER, STRING, RIGHT_BRACE, STRING, EOF,
+				}))
+				Expect(result[8].Literal).To(Equal(" end"))
+			})
+
+			It("should leave an escaped dollar sign alone", func() {
+				result, err := lexer.ScanLine(`"\${a}"`)
+				Expect(err).To(BeNil())
+				Expect(result).To(HaveLen(1))
+				Expect(result[0].Literal).To(Equal("${a}"))
+			})
+
+			It("should return an error when the interpolation is never closed", func() {
+				_, err := New(`"a ${b`).Scan()
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("unterminated string interpolation"))
+			})
+		})
 	})
 
 	Context("numbers", func() {
@@ -159,6 +278,9 @@ var _ = Describe("Lexer", func() {
 					Literal: 1,
 					Lexeme:  "1",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -173,6 +295,9 @@ var _ = Describe("Lexer", func() {
 					Literal: 123,
 					Lexeme:  "123",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     3,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -187,22 +312,72 @@ var _ = Describe("Lexer", func() {
 					Literal: 123,
 					Lexeme:  "123",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     3,
 				}
 				token2 := Token{
 					Type:    SEMICOLON,
 					Literal: ";",
 					Lexeme:  ";",
 					Line:    1,
+					Column:  4,
+					Start:   3,
+					End:     4,
 				}
 				Expect(result).To(Equal([]Token{token1, token2}))
 			})
 		})
 
+		When("its a decimal number", func() {
+			It("should return a list with just a single float token", func() {
+				in := "3.14"
+				result, _ := lexer.ScanLine(in)
+				expected := Token{
+					Type:    NUMBER,
+					Literal: 3.14,
+					Lexeme:  "3.14",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     4,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+		})
+
+		When("its a number followed by a dot and an identifier", func() {
+			It("should return an int token followed by a dot token", func() {
+				in := "1.foo"
+				result, _ := lexer.ScanLine(in)
+				Expect(result).To(HaveLen(3))
+				Expect(result[0]).To(Equal(Token{
+					Type:    NUMBER,
+					Literal: 1,
+					Lexeme:  "1",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
+				}))
+				Expect(result[1].Type).To(Equal(DOT))
+				Expect(result[2].Type).To(Equal(IDENTIFIER))
+			})
+		})
+
+		When("its a decimal number with alpha characters after the fraction", func() {
+			It("should return an error", func() {
+				in := "1.5abc"
+				_, err := lexer.ScanLine(in)
+				Expect(err.Error()).To(ContainSubstring("invalid number: contains alphabetic characters"))
+			})
+		})
+
 		When("its a digit with alpha characters in it", func() {
 			It("should return an error", func() {
 				in := "123abc"
 				_, err := lexer.ScanLine(in)
-				Expect(err).To(Equal(errors.New("invalid number: contains alphabetic characters")))
+				Expect(err.Error()).To(ContainSubstring("invalid number: contains alphabetic characters"))
 			})
 		})
 	})
@@ -217,6 +392,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "==",
 					Lexeme:  "==",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -229,6 +407,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "!=",
 					Lexeme:  "!=",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -243,6 +424,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "<",
 					Lexeme:  "<",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -255,6 +439,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "<=",
 					Lexeme:  "<=",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -269,6 +456,9 @@ var _ = Describe("Lexer", func() {
 					Literal: ">",
 					Lexeme:  ">",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -281,6 +471,9 @@ var _ = Describe("Lexer", func() {
 					Literal: ">=",
 					Lexeme:  ">=",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -297,6 +490,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "{",
 					Lexeme:  "{",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -311,6 +507,86 @@ var _ = Describe("Lexer", func() {
 					Literal: "(",
 					Lexeme:  "(",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+		})
+		When("given a pair of brackets", func() {
+			It("should return a list with a left and right bracket token", func() {
+				in := "[]"
+				result, _ := lexer.ScanLine(in)
+				token1 := Token{
+					Type:    LEFT_BRACKET,
+					Literal: "[",
+					Lexeme:  "[",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
+				}
+				token2 := Token{
+					Type:    RIGHT_BRACKET,
+					Literal: "]",
+					Lexeme:  "]",
+					Line:    1,
+					Column:  2,
+					Start:   1,
+					End:     2,
+				}
+				Expect(result).To(Equal([]Token{token1, token2}))
+			})
+		})
+	})
+
+	Context("punctuation", func() {
+		When("given a dot", func() {
+			It("should return a list with just a dot token", func() {
+				in := "."
+				result, _ := lexer.ScanLine(in)
+				expected := Token{
+					Type:    DOT,
+					Literal: ".",
+					Lexeme:  ".",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+		})
+
+		When("given a comma", func() {
+			It("should return a list with just a comma token", func() {
+				in := ","
+				result, _ := lexer.ScanLine(in)
+				expected := Token{
+					Type:    COMMA,
+					Literal: ",",
+					Lexeme:  ",",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+		})
+		When("given a colon", func() {
+			It("should return a list with just a colon token", func() {
+				in := ":"
+				result, _ := lexer.ScanLine(in)
+				expected := Token{
+					Type:    COLON,
+					Literal: ":",
+					Lexeme:  ":",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -327,6 +603,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "/",
 					Lexeme:  "/",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -341,6 +620,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "+",
 					Lexeme:  "+",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -355,6 +637,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "-",
 					Lexeme:  "-",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -369,10 +654,80 @@ var _ = Describe("Lexer", func() {
 					Literal: "*",
 					Lexeme:  "*",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+		})
+		When("entering a double star", func() {
+			It("should return a list with a single power token", func() {
+				in := "**"
+				result, _ := lexer.ScanLine(in)
+				expected := Token{
+					Type:    STAR_STAR,
+					Literal: "**",
+					Lexeme:  "**",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
 		})
+
+		When("entering a percent", func() {
+			It("should return a list with a percent token", func() {
+				in := "%"
+				result, _ := lexer.ScanLine(in)
+				expected := Token{
+					Type:    PERCENT,
+					Literal: "%",
+					Lexeme:  "%",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+		})
+
+		When("entering a tilde slash", func() {
+			It("should return a list with a floor division token", func() {
+				in := "~/"
+				result, _ := lexer.ScanLine(in)
+				expected := Token{
+					Type:    TILDE_SLASH,
+					Literal: "~/",
+					Lexeme:  "~/",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			})
+		})
+
+		When("entering a tilde on its own", func() {
+			It("should return an error", func() {
+				in := "~"
+				_, err := lexer.ScanLine(in)
+				Expect(err).ToNot(BeNil())
+			})
+		})
+
+		When("entering a double slash", func() {
+			It("should still treat it as a comment", func() {
+				in := "7 // 2"
+				result, _ := lexer.ScanLine(in)
+				Expect(result).To(HaveLen(1))
+				Expect(result[0].Type).To(Equal(NUMBER))
+			})
+		})
 	})
 
 	Context("multiple tokens", func() {
@@ -385,12 +740,18 @@ var _ = Describe("Lexer", func() {
 					Literal: "=",
 					Lexeme:  "=",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     1,
 				}
 				exp2 := Token{
 					Type:    SEMICOLON,
 					Literal: ";",
 					Lexeme:  ";",
 					Line:    1,
+					Column:  2,
+					Start:   1,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{exp1, exp2}))
 			})
@@ -405,12 +766,18 @@ var _ = Describe("Lexer", func() {
 					Literal: "foo",
 					Lexeme:  "\"foo\"",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     5,
 				}
 				exp2 := Token{
 					Type:    STRING,
 					Literal: "bar",
 					Lexeme:  "\"bar\"",
 					Line:    1,
+					Column:  7,
+					Start:   6,
+					End:     11,
 				}
 				Expect(result).To(Equal([]Token{exp1, exp2}))
 			})
@@ -427,6 +794,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "var",
 					Lexeme:  "var",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     3,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -441,12 +811,18 @@ var _ = Describe("Lexer", func() {
 					Literal: "true",
 					Lexeme:  "true",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     4,
 				}
 				token2 := Token{
 					Type:    FALSE,
 					Literal: "false",
 					Lexeme:  "false",
 					Line:    1,
+					Column:  6,
+					Start:   5,
+					End:     10,
 				}
 				Expect(result).To(Equal([]Token{token1, token2}))
 			})
@@ -461,6 +837,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "fn",
 					Lexeme:  "fn",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{token}))
 			})
@@ -475,6 +854,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "and",
 					Lexeme:  "and",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     3,
 				}
 				Expect(result).To(Equal([]Token{token}))
 			})
@@ -489,6 +871,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "or",
 					Lexeme:  "or",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     2,
 				}
 				Expect(result).To(Equal([]Token{token}))
 			})
@@ -502,10 +887,74 @@ var _ = Describe("Lexer", func() {
 					Literal: "print",
 					Lexeme:  "print",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     5,
 				}
 				Expect(result).To(Equal([]Token{token}))
 			})
 		})
+
+		When("given the class, this and super keywords", func() {
+			It("returns a token list with all class keywords", func() {
+				in := "class this super"
+				result, _ := lexer.ScanLine(in)
+				token1 := Token{
+					Type:    CLASS,
+					Literal: "class",
+					Lexeme:  "class",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     5,
+				}
+				token2 := Token{
+					Type:    THIS,
+					Literal: "this",
+					Lexeme:  "this",
+					Line:    1,
+					Column:  7,
+					Start:   6,
+					End:     10,
+				}
+				token3 := Token{
+					Type:    SUPER,
+					Literal: "super",
+					Lexeme:  "super",
+					Line:    1,
+					Column:  12,
+					Start:   11,
+					End:     16,
+				}
+				Expect(result).To(Equal([]Token{token1, token2, token3}))
+			})
+		})
+
+		When("given the break and continue keywords", func() {
+			It("returns a token list with both loop control keywords", func() {
+				in := "break continue"
+				result, _ := lexer.ScanLine(in)
+				token1 := Token{
+					Type:    BREAK,
+					Literal: "break",
+					Lexeme:  "break",
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     5,
+				}
+				token2 := Token{
+					Type:    CONTINUE,
+					Literal: "continue",
+					Lexeme:  "continue",
+					Line:    1,
+					Column:  7,
+					Start:   6,
+					End:     14,
+				}
+				Expect(result).To(Equal([]Token{token1, token2}))
+			})
+		})
 	})
 
 	Context("identifiers", func() {
@@ -518,6 +967,9 @@ var _ = Describe("Lexer", func() {
 					Literal: "foo",
 					Lexeme:  "foo",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     3,
 				}
 				Expect(result).To(Equal([]Token{expected}))
 			})
@@ -532,12 +984,18 @@ var _ = Describe("Lexer", func() {
 					Literal: "var",
 					Lexeme:  "var",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     3,
 				}
 				token2 := Token{
 					Type:    IDENTIFIER,
 					Literal: "foo",
 					Lexeme:  "foo",
 					Line:    1,
+					Column:  5,
+					Start:   4,
+					End:     7,
 				}
 				Expect(result).To(Equal([]Token{token1, token2}))
 			})
@@ -553,24 +1011,36 @@ var _ = Describe("Lexer", func() {
 						Literal: "var",
 						Lexeme:  "var",
 						Line:    1,
+						Column:  1,
+						Start:   0,
+						End:     3,
 					},
 					{
 						Type:    IDENTIFIER,
 						Literal: "foo",
 						Lexeme:  "foo",
 						Line:    1,
+						Column:  5,
+						Start:   4,
+						End:     7,
 					},
 					{
 						Type:    EQUAL,
 						Literal: "=",
 						Lexeme:  "=",
 						Line:    1,
+						Column:  9,
+						Start:   8,
+						End:     9,
 					},
 					{
 						Type:    NUMBER,
 						Literal: 3,
 						Lexeme:  "3",
 						Line:    1,
+						Column:  11,
+						Start:   10,
+						End:     11,
 					},
 				}))
 			})
@@ -585,15 +1055,136 @@ var _ = Describe("Lexer", func() {
 					Literal: "foo",
 					Lexeme:  "foo",
 					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     3,
 				}
 				token2 := Token{
 					Type:    LEFT_PAREN,
 					Literal: "(",
 					Lexeme:  "(",
 					Line:    1,
+					Column:  4,
+					Start:   3,
+					End:     4,
 				}
 				Expect(result).To(Equal([]Token{token1, token2}))
 			})
 		})
+		DescribeTable("given an identifier with more than ascii letters",
+			func(in string) {
+				result, err := lexer.ScanLine(in)
+				Expect(err).To(BeNil())
+				expected := Token{
+					Type:    IDENTIFIER,
+					Literal: in,
+					Lexeme:  in,
+					Line:    1,
+					Column:  1,
+					Start:   0,
+					End:     int64(len(in)),
+				}
+				Expect(result).To(Equal([]Token{expected}))
+			},
+			Entry("underscores", "_private_name"),
+			Entry("digits after the first character", "a1b2"),
+			Entry("accented letters", "größe"),
+			Entry("letters from another script", "変数"),
+		)
+
+		When("given an identifier followed by a multi-byte character in a string", func() {
+			It("returns the identifier and the whole string", func() {
+				result, err := lexer.ScanLine(`name"🥝"`)
+				Expect(err).To(BeNil())
+				Expect(result).To(HaveLen(2))
+				Expect(result[0].Lexeme).To(Equal("name"))
+				Expect(result[1].Literal).To(Equal("🥝"))
+			})
+		})
+	})
+
+	Context("unknown characters", func() {
+		DescribeTable("returns an error with the character and its position",
+			func(in string, message string) {
+				_, err := New(in).Scan()
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring(message))
+			},
+			Entry("a symbol kiwi doesn't use", "var a = 1 @ 2;", "unexpected character '@'"),
+			Entry("a symbol after multi-byte characters", `var é = "ü" # 2;`, "column 13"),
+			Entry("a symbol on a later line", "var a = 1;\n  ^", "line 2, column 3"),
+			Entry("bytes that aren't valid UTF-8", "var a = \xff;", "invalid UTF-8 encoding"),
+		)
+	})
+
+	Context("positions", func() {
+		type span struct {
+			Lexeme string
+			Line   int64
+			Column int64
+			Start  int64
+			End    int64
+		}
+		spans := func(tokens []Token) []span {
+			result := []span{}
+			for _, t := range tokens {
+				result = append(result, span{t.Lexeme, t.Line, t.Column, t.Start, t.End})
+			}
+			return result
+		}
+
+		When("scanning a whole program", func() {
+			It("should number lines from 1 and give every token its span", func() {
+				result, err := New("var a = 1;\n// note\nprint a;").Scan()
+				Expect(err).To(BeNil())
+				Expect(spans(result)).To(Equal([]span{
+					{"var", 1, 1, 0, 3},
+					{"a", 1, 5, 4, 5},
+					{"=", 1, 7, 6, 7},
+					{"1", 1, 9, 8, 9},
+					{";", 1, 10, 9, 10},
+					{"print", 3, 1, 19, 24},
+					{"a", 3, 7, 25, 26},
+					{";", 3, 8, 26, 27},
+					{"", 3, 9, 27, 27},
+				}))
+			})
+		})
+
+		When("a line has multi-byte characters", func() {
+			It("should count columns in characters and offsets in bytes", func() {
+				result, err := New(`var é = "ü";`).Scan()
+				Expect(err).To(BeNil())
+				Expect(spans(result)[1:4]).To(Equal([]span{
+					{"é", 1, 5, 4, 6},
+					{"=", 1, 7, 7, 8},
+					{`"ü"`, 1, 9, 9, 13},
+				}))
+			})
+		})
+
+		When("a token spans several lines", func() {
+			It("should place it on the line it starts on and count the lines inside it", func() {
+				result, err := New("`a\nb`\nnext").Scan()
+				Expect(err).To(BeNil())
+				Expect(spans(result)[:2]).To(Equal([]span{
+					{"`a\nb`", 1, 1, 0, 5},
+					{"next", 3, 1, 6, 10},
+				}))
+			})
+		})
+
+		When("scanning one line at a time", func() {
+			It("should number the lines the same way a whole program does", func() {
+				first, err := lexer.ScanLine("var a;")
+				Expect(err).To(BeNil())
+				second, err := lexer.ScanLine("  print a;")
+				Expect(err).To(BeNil())
+				Expect(first[0].Line).To(Equal(int64(1)))
+				Expect(second[0].Line).To(Equal(int64(2)))
+				Expect(second[0].Column).To(Equal(int64(3)))
+				Expect(second[0].Start).To(Equal(int64(2)))
+			})
+		})
 	})
 })
@@ -1,12 +1,17 @@
 package lexer
 
+import "github.com/maxcelant/kiwi/internal/diagnostics"
+
 type TokenType int
 
 type Token struct {
 	Type    TokenType
 	Literal any
 	Lexeme  string
-	Line    int64
+	Line    int64 // 1-based line the token starts on
+	Column  int64 // 1-based column the token starts at, counted in runes
+	Start   int64 // Byte offset of the first byte of the lexeme
+	End     int64 // Byte offset just past the last byte of the lexeme
 }
 
 const (
@@ -15,10 +20,18 @@ const (
 	RIGHT_PAREN
 	LEFT_BRACE
 	RIGHT_BRACE
+	LEFT_BRACKET
+	RIGHT_BRACKET
+	COMMA
+	COLON
+	DOT
 	PLUS
 	MINUS
 	STAR
+	STAR_STAR
 	SLASH
+	TILDE_SLASH
+	PERCENT
 	BANG
 	BANG_EQ
 	GREATER
@@ -29,6 +42,7 @@ const (
 	EQUAL_EQUAL
 	IDENTIFIER
 	STRING
+	INTERPOLATION
 	NUMBER
 	TRUE
 	FALSE
@@ -42,7 +56,16 @@ const (
 	PRINT
 	WHILE
 	RETURN
+	BREAK
+	CONTINUE
 	CLASS
+	THIS
+	SUPER
 	FUNC
 	EOF
 )
+
+// Where the token sits in the source, for pointing diagnostics at it
+func (t Token) Span() diagnostics.Span {
+	return diagnostics.Span{Line: t.Line, Column: t.Column, Start: t.Start, End: t.End}
+}
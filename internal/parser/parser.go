@@ -4,18 +4,26 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/maxcelant/kiwi/internal/diagnostics"
 	exp "github.com/maxcelant/kiwi/internal/expr"
 	"github.com/maxcelant/kiwi/internal/lexer"
 	"github.com/maxcelant/kiwi/internal/stmt"
 )
 
 type Parser struct {
-	tokens  []lexer.Token
-	current int
+	tokens     []lexer.Token
+	current    int
+	loopDepth  int // How many loop bodies we are currently nested in
+	funcDepth  int // How many function bodies we are currently nested in
+	blockDepth int // How many blocks we are currently nested in
+	errs       diagnostics.List
 }
 
 func New(tokens []lexer.Token) *Parser {
-	return &Parser{tokens, 0}
+	return &Parser{
+		tokens:  tokens,
+		current: 0,
+	}
 }
 
 func (p *Parser) Parse() ([]stmt.Stmt, error) {
@@ -26,20 +34,187 @@ func (p *Parser) Parse() ([]stmt.Stmt, error) {
 	for !p.isAtEnd() {
 		s, err := p.declaration()
 		if err != nil {
-			return nil, fmt.Errorf("parsing error occurred: %w", err)
+			p.recover(err)
+			continue
 		}
 		statements = append(statements, s)
 	}
+	if len(p.errs) > 0 {
+		return nil, p.errs
+	}
 	return statements, nil
 }
 
+// Records the error and skips to the next statement, so that parsing can carry
+// on and report everything that is wrong in a single pass
+func (p *Parser) recover(err error) {
+	p.errs = append(p.errs, p.asError(err))
+	p.synchronize()
+}
+
+// Skips ahead to what is most likely the start of the next statement, either
+// just past a ';' or at a keyword that begins one. Inside of a block it also
+// stops at the '}' that closes it, so the block can finish parsing and the
+// statements after it aren't mistaken for top level ones. Anything between a
+// '{' and its '}' is skipped as a whole. This keeps a single mistake from
+// cascading into a pile of errors for the code that follows it.
+func (p *Parser) synchronize() {
+	if !p.check(lexer.RIGHT_BRACE) || p.blockDepth == 0 {
+		p.advance()
+	}
+	depth := 0
+	if p.prev().Type == lexer.LEFT_BRACE {
+		depth += 1
+	}
+	for !p.isAtEnd() {
+		if depth == 0 && p.prev().Type == lexer.SEMICOLON {
+			return
+		}
+		switch p.tokens[p.current].Type {
+		case lexer.CLASS, lexer.FUNC, lexer.VAR, lexer.FOR, lexer.IF, lexer.WHILE,
+			lexer.PRINT, lexer.RETURN, lexer.BREAK, lexer.CONTINUE:
+			if depth == 0 {
+				return
+			}
+		case lexer.LEFT_BRACE:
+			depth += 1
+		case lexer.RIGHT_BRACE:
+			if depth == 0 && p.blockDepth > 0 {
+				return
+			}
+			if depth > 0 {
+				depth -= 1
+			}
+		}
+		p.advance()
+	}
+}
+
+func (p *Parser) error(token lexer.Token, message string) *diagnostics.Diagnostic {
+	return diagnostics.New(diagnostics.Error, token.Span(), message)
+}
+
+// Errors from the parser itself already know their token, anything else is
+// placed at the token the parser stopped on
+func (p *Parser) asError(err error) *diagnostics.Diagnostic {
+	var d *diagnostics.Diagnostic
+	if errors.As(err, &d) {
+		return d
+	}
+	return p.error(p.tokens[p.current], err.Error())
+}
+
 func (p *Parser) declaration() (stmt.Stmt, error) {
+	if p.match(lexer.CLASS) {
+		return p.classDeclaration()
+	}
+	if p.match(lexer.FUNC) {
+		return p.function("function")
+	}
 	if p.match(lexer.VAR) {
 		return p.varDeclaration()
 	}
 	return p.statement()
 }
 
+func (p *Parser) classDeclaration() (stmt.Stmt, error) {
+	var superclass *exp.Variable
+	name, err := p.consume(lexer.IDENTIFIER, "expect class name")
+	if err != nil {
+		return nil, err
+	}
+	if p.match(lexer.LESS) {
+		superName, err := p.consume(lexer.IDENTIFIER, "expect superclass name")
+		if err != nil {
+			return nil, err
+		}
+		superclass = &exp.Variable{Name: superName}
+	}
+	_, err = p.consume(lexer.LEFT_BRACE, "expect '{' before class body")
+	if err != nil {
+		return nil, err
+	}
+
+	// Methods are declared like functions, just without the 'fn' keyword
+	methods := []stmt.Function{}
+	for !p.check(lexer.RIGHT_BRACE) && !p.isAtEnd() {
+		method, err := p.function("method")
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+	}
+	_, err = p.consume(lexer.RIGHT_BRACE, "expect '}' after class body")
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.Class{
+		Name:       name,
+		Superclass: superclass,
+		Methods:    methods,
+	}, nil
+}
+
+// The kind lets us reuse this for other callable declarations while still
+// producing errors that make sense for each of them
+func (p *Parser) function(kind string) (stmt.Function, error) {
+	name, err := p.consume(lexer.IDENTIFIER, fmt.Sprintf("expect %s name", kind))
+	if err != nil {
+		return stmt.Function{}, err
+	}
+	_, err = p.consume(lexer.LEFT_PAREN, fmt.Sprintf("expect '(' after %s name", kind))
+	if err != nil {
+		return stmt.Function{}, err
+	}
+
+	params := []lexer.Token{}
+	if !p.check(lexer.RIGHT_PAREN) {
+		for {
+			param, err := p.consume(lexer.IDENTIFIER, "expect parameter name")
+			if err != nil {
+				return stmt.Function{}, err
+			}
+			params = append(params, param)
+			if !p.match(lexer.COMMA) {
+				break
+			}
+		}
+	}
+	_, err = p.consume(lexer.RIGHT_PAREN, "expect ')' after parameters")
+	if err != nil {
+		return stmt.Function{}, err
+	}
+
+	_, err = p.consume(lexer.LEFT_BRACE, fmt.Sprintf("expect '{' before %s body", kind))
+	if err != nil {
+		return stmt.Function{}, err
+	}
+	body, err := p.functionBody()
+	if err != nil {
+		return stmt.Function{}, err
+	}
+
+	return stmt.Function{
+		Name:   name,
+		Params: params,
+		Body:   body,
+	}, nil
+}
+
+// A function body starts a fresh loop context, since a 'break' inside of it
+// can't jump out of a loop that surrounds the declaration
+func (p *Parser) functionBody() ([]stmt.Stmt, error) {
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
+	p.funcDepth += 1
+	defer func() {
+		p.loopDepth = loopDepth
+		p.funcDepth -= 1
+	}()
+	return p.block()
+}
+
 func (p *Parser) varDeclaration() (stmt.Stmt, error) {
 	var err error
 	var initializer exp.Expr
@@ -64,6 +239,24 @@ func (p *Parser) varDeclaration() (stmt.Stmt, error) {
 }
 
 func (p *Parser) statement() (stmt.Stmt, error) {
+	if p.match(lexer.IF) {
+		return p.ifStatement()
+	}
+	if p.match(lexer.WHILE) {
+		return p.whileStatement()
+	}
+	if p.match(lexer.FOR) {
+		return p.forStatement()
+	}
+	if p.match(lexer.BREAK) {
+		return p.breakStatement()
+	}
+	if p.match(lexer.CONTINUE) {
+		return p.continueStatement()
+	}
+	if p.match(lexer.RETURN) {
+		return p.returnStatement()
+	}
 	if p.match(lexer.PRINT) {
 		return p.printStatement()
 	}
@@ -73,12 +266,213 @@ func (p *Parser) statement() (stmt.Stmt, error) {
 	return p.expressionStatement()
 }
 
+func (p *Parser) ifStatement() (stmt.Stmt, error) {
+	var elseBranch stmt.Stmt
+	_, err := p.consume(lexer.LEFT_PAREN, "expect '(' after 'if'")
+	if err != nil {
+		return nil, err
+	}
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.consume(lexer.RIGHT_PAREN, "expect ')' after if condition")
+	if err != nil {
+		return nil, err
+	}
+
+	thenBranch, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+	// The 'else' binds to the nearest 'if' that precedes it, since the inner
+	// 'if' statement greedily looks for its own 'else' before returning
+	if p.match(lexer.ELSE) {
+		elseBranch, err = p.statement()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stmt.If{
+		Condition:  condition,
+		ThenBranch: thenBranch,
+		ElseBranch: elseBranch,
+	}, nil
+}
+
+func (p *Parser) whileStatement() (stmt.Stmt, error) {
+	_, err := p.consume(lexer.LEFT_PAREN, "expect '(' after 'while'")
+	if err != nil {
+		return nil, err
+	}
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.consume(lexer.RIGHT_PAREN, "expect ')' after while condition")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.loopBody()
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.While{
+		Condition: condition,
+		Body:      body,
+	}, nil
+}
+
+// The for loop has no node of its own, it gets desugared into the equivalent
+// while loop: `{ init; while (cond) body }`. The increment is kept on the while
+// node so that it still runs when the body is cut short by a 'continue'
+func (p *Parser) forStatement() (stmt.Stmt, error) {
+	var err error
+	var initializer stmt.Stmt
+	var condition exp.Expr
+	var increment exp.Expr
+	_, err = p.consume(lexer.LEFT_PAREN, "expect '(' after 'for'")
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(lexer.SEMICOLON) {
+		initializer = nil
+	} else if p.match(lexer.VAR) {
+		initializer, err = p.varDeclaration()
+	} else {
+		initializer, err = p.expressionStatement()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.check(lexer.SEMICOLON) {
+		condition, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = p.consume(lexer.SEMICOLON, "expect ';' after loop condition")
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.check(lexer.RIGHT_PAREN) {
+		increment, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = p.consume(lexer.RIGHT_PAREN, "expect ')' after for clauses")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.loopBody()
+	if err != nil {
+		return nil, err
+	}
+
+	// An omitted condition means the loop runs forever
+	if condition == nil {
+		condition = exp.Primary{Value: true}
+	}
+	body = stmt.While{
+		Condition: condition,
+		Body:      body,
+		Increment: increment,
+	}
+	if initializer != nil {
+		body = stmt.Block{
+			Statements: []stmt.Stmt{initializer, body},
+		}
+	}
+
+	return body, nil
+}
+
+// Parses the body of a loop while keeping track of how deeply nested we are,
+// so 'break' and 'continue' know whether they have a loop to jump out of
+func (p *Parser) loopBody() (stmt.Stmt, error) {
+	p.loopDepth += 1
+	defer func() { p.loopDepth -= 1 }()
+	return p.statement()
+}
+
+func (p *Parser) breakStatement() (stmt.Stmt, error) {
+	keyword := p.prev()
+	if p.loopDepth == 0 {
+		return nil, p.error(keyword, "'break' must be inside a loop")
+	}
+	_, err := p.consume(lexer.SEMICOLON, "expect ';' after 'break'")
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Break{Keyword: keyword}, nil
+}
+
+func (p *Parser) continueStatement() (stmt.Stmt, error) {
+	keyword := p.prev()
+	if p.loopDepth == 0 {
+		return nil, p.error(keyword, "'continue' must be inside a loop")
+	}
+	_, err := p.consume(lexer.SEMICOLON, "expect ';' after 'continue'")
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Continue{Keyword: keyword}, nil
+}
+
+func (p *Parser) returnStatement() (stmt.Stmt, error) {
+	var err error
+	var value exp.Expr
+	keyword := p.prev()
+	if p.funcDepth == 0 {
+		return nil, p.error(keyword, "'return' must be inside a function")
+	}
+	if !p.check(lexer.SEMICOLON) {
+		value, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = p.consume(lexer.SEMICOLON, "expect ';' after return value")
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Return{
+		Keyword: keyword,
+		Value:   value,
+	}, nil
+}
+
 func (p *Parser) blockStatement() (stmt.Stmt, error) {
+	stmts, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Block{
+		Statements: stmts,
+	}, nil
+}
+
+// Parses the declarations of a block, assuming the opening '{' was already consumed
+func (p *Parser) block() ([]stmt.Stmt, error) {
+	p.blockDepth += 1
+	defer func() { p.blockDepth -= 1 }()
+
 	var stmts []stmt.Stmt
 	for !p.check(lexer.RIGHT_BRACE) && !p.isAtEnd() {
 		s, err := p.declaration()
 		if err != nil {
-			return nil, err
+			// Recovering here rather than at the top level keeps the rest of
+			// the block, and whether it's inside a loop or function, intact
+			p.recover(err)
+			continue
 		}
 		stmts = append(stmts, s)
 	}
@@ -87,10 +481,7 @@ func (p *Parser) blockStatement() (stmt.Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	return stmt.Block{
-		Statements: stmts,
-	}, nil
+	return stmts, nil
 }
 
 func (p *Parser) printStatement() (stmt.Stmt, error) {
@@ -127,26 +518,43 @@ func (p *Parser) expression() (exp.Expr, error) {
 
 func (p *Parser) assignment() (exp.Expr, error) {
 	expr, err := p.logicOR()
+	if err != nil {
+		return nil, err
+	}
 
 	if p.match(lexer.EQUAL) {
+		equals := p.prev()
 		value, err := p.assignment()
 		if err != nil {
 			return nil, err
 		}
 		// This allows us to verify the left-hand value is an expression that *can*
 		// have something assigned to it, otherwise, this in invalid
-		variable, ok := expr.(exp.Variable)
-		if !ok {
-			return nil, fmt.Errorf("invalid assignment target")
-		}
-		name := variable.Name
-		return exp.Assign{
-			Name:  name,
-			Value: value,
-		}, nil
+		switch target := expr.(type) {
+		case *exp.Variable:
+			return &exp.Assign{
+				Name:  target.Name,
+				Value: value,
+			}, nil
+		case exp.Get:
+			return exp.Set{
+				Object: target.Object,
+				Name:   target.Name,
+				Value:  value,
+			}, nil
+		case exp.Index:
+			return exp.IndexSet{
+				Object:  target.Object,
+				Bracket: target.Bracket,
+				Index:   target.Index,
+				Value:   value,
+			}, nil
+		default:
+			return nil, p.error(equals, "invalid assignment target")
+		}
 	}
 
-	return expr, err
+	return expr, nil
 }
 
 func (p *Parser) logicOR() (exp.Expr, error) {
@@ -247,7 +655,7 @@ func (p *Parser) term() (exp.Expr, error) {
 func (p *Parser) factor() (exp.Expr, error) {
 	expr, err := p.unary()
 
-	for p.match(lexer.SLASH, lexer.STAR) {
+	for p.match(lexer.SLASH, lexer.STAR, lexer.PERCENT, lexer.TILDE_SLASH) {
 		operator := p.prev()
 		right, err := p.unary()
 		if err != nil {
@@ -276,7 +684,134 @@ func (p *Parser) unary() (exp.Expr, error) {
 		}, nil
 	}
 
-	return p.primary()
+	return p.power()
+}
+
+// Power binds tighter than unary minus, so `-2 ** 2` is -4. It is also right
+// associative, which falls out of parsing the exponent as another unary.
+func (p *Parser) power() (exp.Expr, error) {
+	expr, err := p.call()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(lexer.STAR_STAR) {
+		operator := p.prev()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return exp.Binary{
+			Right:    right,
+			Operator: operator,
+			Left:     expr,
+		}, nil
+	}
+
+	return expr, nil
+}
+
+func (p *Parser) call() (exp.Expr, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	// Calls, property accesses and indexes can be chained, such as `makeCounter()()`, `a.b().c` or `grid[i][j]`
+	for {
+		if p.match(lexer.LEFT_PAREN) {
+			expr, err = p.finishCall(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(lexer.LEFT_BRACKET) {
+			expr, err = p.finishIndex(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(lexer.DOT) {
+			name, err := p.consume(lexer.IDENTIFIER, "expect property name after '.'")
+			if err != nil {
+				return nil, err
+			}
+			expr = exp.Get{
+				Object: expr,
+				Name:   name,
+			}
+		} else {
+			break
+		}
+	}
+
+	return expr, nil
+}
+
+// Parses the rest of either an index like `xs[i]` or a slice like `xs[i:j]`,
+// where both of the slice's bounds are optional
+func (p *Parser) finishIndex(object exp.Expr) (exp.Expr, error) {
+	var start exp.Expr
+	var err error
+	if !p.check(lexer.COLON) {
+		start, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.match(lexer.COLON) {
+		var end exp.Expr
+		if !p.check(lexer.RIGHT_BRACKET) {
+			end, err = p.expression()
+			if err != nil {
+				return nil, err
+			}
+		}
+		bracket, err := p.consume(lexer.RIGHT_BRACKET, "expect ']' after slice")
+		if err != nil {
+			return nil, err
+		}
+		return exp.Slice{
+			Object:  object,
+			Bracket: bracket,
+			Start:   start,
+			End:     end,
+		}, nil
+	}
+
+	bracket, err := p.consume(lexer.RIGHT_BRACKET, "expect ']' after index")
+	if err != nil {
+		return nil, err
+	}
+	return exp.Index{
+		Object:  object,
+		Bracket: bracket,
+		Index:   start,
+	}, nil
+}
+
+func (p *Parser) finishCall(callee exp.Expr) (exp.Expr, error) {
+	args := []exp.Expr{}
+	if !p.check(lexer.RIGHT_PAREN) {
+		for {
+			arg, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.match(lexer.COMMA) {
+				break
+			}
+		}
+	}
+	paren, err := p.consume(lexer.RIGHT_PAREN, "expect ')' after arguments")
+	if err != nil {
+		return nil, err
+	}
+	return exp.Call{
+		Callee:    callee,
+		Paren:     paren,
+		Arguments: args,
+	}, nil
 }
 
 func (p *Parser) primary() (exp.Expr, error) {
@@ -292,11 +827,29 @@ func (p *Parser) primary() (exp.Expr, error) {
 	if p.match(lexer.STRING) {
 		return exp.Primary{Value: p.prev().Literal}, nil
 	}
+	if p.match(lexer.INTERPOLATION) {
+		return p.interpolation()
+	}
 	if p.match(lexer.NUMBER) {
 		return exp.Primary{Value: p.prev().Literal}, nil
 	}
+	if p.match(lexer.THIS) {
+		return &exp.This{Keyword: p.prev()}, nil
+	}
+	if p.match(lexer.SUPER) {
+		keyword := p.prev()
+		_, err := p.consume(lexer.DOT, "expect '.' after 'super'")
+		if err != nil {
+			return nil, err
+		}
+		method, err := p.consume(lexer.IDENTIFIER, "expect superclass method name")
+		if err != nil {
+			return nil, err
+		}
+		return &exp.Super{Keyword: keyword, Method: method}, nil
+	}
 	if p.match(lexer.IDENTIFIER) {
-		return exp.Variable{Name: p.prev()}, nil
+		return &exp.Variable{Name: p.prev()}, nil
 	}
 	if p.match(lexer.LEFT_PAREN) {
 		expr, err := p.expression()
@@ -309,9 +862,91 @@ func (p *Parser) primary() (exp.Expr, error) {
 		}
 		return exp.Grouping{Expression: expr}, nil
 	}
+	if p.match(lexer.LEFT_BRACKET) {
+		return p.list()
+	}
+	// A '{' that starts a statement is always a block, so a map literal can only
+	// show up where an expression is expected, such as `var m = {}` or `print {}`
+	if p.match(lexer.LEFT_BRACE) {
+		return p.mapLiteral(p.prev())
+	}
+
+	token := p.tokens[p.current]
+	return nil, p.error(token, fmt.Sprintf("%s expected expression", token.Lexeme))
+}
+
+// The lexer has already split the string up, so this just alternates between
+// the literal pieces and the embedded expressions until the final STRING token
+func (p *Parser) interpolation() (exp.Expr, error) {
+	parts := []exp.Expr{}
+	for {
+		parts = append(parts, exp.Primary{Value: p.prev().Literal})
+		embedded, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, embedded)
+		if !p.match(lexer.INTERPOLATION) {
+			break
+		}
+	}
+	end, err := p.consume(lexer.STRING, "expect '}' after interpolated expression")
+	if err != nil {
+		return nil, err
+	}
+	parts = append(parts, exp.Primary{Value: end.Literal})
+	return exp.Interpolation{Parts: parts}, nil
+}
 
-	token, _ := p.peek()
-	return nil, fmt.Errorf("%s expected expression", token.Lexeme)
+func (p *Parser) list() (exp.Expr, error) {
+	elements := []exp.Expr{}
+	if !p.check(lexer.RIGHT_BRACKET) {
+		for {
+			element, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+			if !p.match(lexer.COMMA) {
+				break
+			}
+		}
+	}
+	_, err := p.consume(lexer.RIGHT_BRACKET, "expect ']' after list elements")
+	if err != nil {
+		return nil, err
+	}
+	return exp.List{Elements: elements}, nil
+}
+
+func (p *Parser) mapLiteral(brace lexer.Token) (exp.Expr, error) {
+	m := exp.Map{Brace: brace, Keys: []exp.Expr{}, Values: []exp.Expr{}}
+	if !p.check(lexer.RIGHT_BRACE) {
+		for {
+			key, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			_, err = p.consume(lexer.COLON, "expect ':' after map key")
+			if err != nil {
+				return nil, err
+			}
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			m.Keys = append(m.Keys, key)
+			m.Values = append(m.Values, value)
+			if !p.match(lexer.COMMA) {
+				break
+			}
+		}
+	}
+	_, err := p.consume(lexer.RIGHT_BRACE, "expect '}' after map entries")
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
 func (p *Parser) match(matchers ...lexer.TokenType) bool {
@@ -352,12 +987,12 @@ func (p *Parser) peek() (lexer.Token, error) {
 func (p *Parser) consume(tokenType lexer.TokenType, errMsg string) (lexer.Token, error) {
 	next, err := p.peek()
 	if err != nil {
-		return lexer.Token{}, fmt.Errorf("%s: %w", errMsg, err)
+		return lexer.Token{}, p.error(p.tokens[p.current], fmt.Sprintf("%s: %s", errMsg, err))
 	}
 	if next.Type == tokenType {
 		return p.advance(), nil
 	}
-	return lexer.Token{}, errors.New(errMsg)
+	return lexer.Token{}, p.error(next, errMsg)
 }
 
 func (p *Parser) isAtEnd() bool {
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/maxcelant/kiwi/internal/diagnostics"
 	"github.com/maxcelant/kiwi/internal/expr"
 	"github.com/maxcelant/kiwi/internal/lexer"
 	"github.com/maxcelant/kiwi/internal/stmt"
@@ -267,6 +269,97 @@ var _ = Describe("Parser", func() {
 					}}))
 				})
 			})
+			When("its a list with two numbers and a percent", func() {
+				It("returns a tree with one factor node", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.NUMBER, Literal: 7, Lexeme: "7", Line: 1},
+						{Type: lexer.PERCENT, Lexeme: "%", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{Expression: expr.Binary{
+						Left:     expr.Primary{Value: 7},
+						Operator: tokens[1],
+						Right:    expr.Primary{Value: 2},
+					}}))
+				})
+			})
+
+			When("its a list with two numbers and a tilde slash", func() {
+				It("returns a tree with one factor node", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.NUMBER, Literal: 7, Lexeme: "7", Line: 1},
+						{Type: lexer.TILDE_SLASH, Lexeme: "~/", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{Expression: expr.Binary{
+						Left:     expr.Primary{Value: 7},
+						Operator: tokens[1],
+						Right:    expr.Primary{Value: 2},
+					}}))
+				})
+			})
+		})
+
+		Describe("Power", func() {
+			When("its a list of multiple numbers and double star tokens", func() {
+				It("returns a right associative tree", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.STAR_STAR, Lexeme: "**", Line: 1},
+						{Type: lexer.NUMBER, Literal: 3, Lexeme: "3", Line: 1},
+						{Type: lexer.STAR_STAR, Lexeme: "**", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{Expression: expr.Binary{
+						Left:     expr.Primary{Value: 2},
+						Operator: tokens[1],
+						Right: expr.Binary{
+							Left:     expr.Primary{Value: 3},
+							Operator: tokens[3],
+							Right:    expr.Primary{Value: 2},
+						},
+					}}))
+				})
+			})
+
+			When("its a list with a negative number raised to a power", func() {
+				It("returns a tree that negates the power", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.MINUS, Lexeme: "-", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.STAR_STAR, Lexeme: "**", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{Expression: expr.Unary{
+						Operator: tokens[0],
+						Right: expr.Binary{
+							Left:     expr.Primary{Value: 2},
+							Operator: tokens[2],
+							Right:    expr.Primary{Value: 2},
+						},
+					}}))
+				})
+			})
 		})
 
 		Describe("Term", func() {
@@ -679,7 +772,7 @@ var _ = Describe("Parser", func() {
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
 					Expect(actual[0]).To(Equal(stmt.Expression{
-						Expression: expr.Assign{
+						Expression: &expr.Assign{
 							Name:  tokens[0],
 							Value: expr.Primary{Value: 42},
 						},
@@ -687,84 +780,136 @@ var _ = Describe("Parser", func() {
 				})
 			})
 		})
-	})
+		Describe("Call", func() {
+			When("its a list with a call with multiple arguments", func() {
+				It("returns a call expression with the arguments in order", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IDENTIFIER, Lexeme: "add", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.COMMA, Lexeme: ",", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Call{
+							Callee:    &expr.Variable{Name: tokens[0]},
+							Paren:     tokens[5],
+							Arguments: []expr.Expr{expr.Primary{Value: 1}, expr.Primary{Value: 2}},
+						},
+					}))
+				})
+			})
 
-	Describe("Statements", func() {
-		Describe("Print", func() {
-			When("its a list with a print token and a string", func() {
-				It("returns a print statement with a string expression", func() {
+			When("its a list with chained calls", func() {
+				It("returns a call expression whose callee is another call", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
-						{Type: lexer.STRING, Literal: "test", Lexeme: "\"test\"", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "make", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
 						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
 						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
-					Expect(actual[0]).To(Equal(stmt.Print{
-						Expression: expr.Primary{Value: "test"},
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Call{
+							Callee: expr.Call{
+								Callee:    &expr.Variable{Name: tokens[0]},
+								Paren:     tokens[2],
+								Arguments: []expr.Expr{},
+							},
+							Paren:     tokens[4],
+							Arguments: []expr.Expr{},
+						},
 					}))
 				})
 			})
 
-			When("its a list with a print token and string but without a semicolon", func() {
+			When("its a list with a call missing its closing parenthesis", func() {
 				It("returns an error", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
-						{Type: lexer.STRING, Literal: "test", Lexeme: "\"test\"", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "add", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
 						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
-					_, err := parser.Parse()
-					Expect(err.Error()).To(ContainSubstring("reached end of file"))
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect ')' after arguments"))
 				})
 			})
 		})
-
-		Describe("Var", func() {
-			When("its a list with a var and an identifier", func() {
-				It("returns a var declaration", func() {
+		Describe("Get and Set", func() {
+			When("its a list with a chained property access", func() {
+				It("returns nested get expressions", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.VAR, Lexeme: "var", Line: 1},
-						{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "a", Line: 1},
+						{Type: lexer.DOT, Lexeme: ".", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "b", Line: 1},
+						{Type: lexer.DOT, Lexeme: ".", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "c", Line: 1},
 						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
 						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
-					Expect(actual[0]).To(Equal(stmt.Var{
-						Name: lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Get{
+							Object: expr.Get{
+								Object: &expr.Variable{Name: tokens[0]},
+								Name:   tokens[2],
+							},
+							Name: tokens[4],
+						},
 					}))
 				})
 			})
 
-			When("its a list with a var, identifier and initializer", func() {
-				It("returns a var declaration with an initializer", func() {
+			When("its a list with an assignment to a property of this", func() {
+				It("returns a set expression", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.VAR, Lexeme: "var", Line: 1},
-						{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
+						{Type: lexer.THIS, Lexeme: "this", Line: 1},
+						{Type: lexer.DOT, Lexeme: ".", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "value", Line: 1},
 						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
-						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
 						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
 						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
-					Expect(actual[0]).To(Equal(stmt.Var{
-						Name:        lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
-						Initializer: expr.Primary{Value: true},
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Set{
+							Object: &expr.This{Keyword: tokens[0]},
+							Name:   tokens[2],
+							Value:  expr.Primary{Value: 1},
+						},
 					}))
 				})
 			})
 
-			When("its a list with a var and a non-identifier token", func() {
+			When("its a list with an assignment to a call result", func() {
 				It("returns an error", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.VAR, Lexeme: "var", Line: 1},
-						{Type: lexer.NUMBER, Lexeme: "123", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "f", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
 						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
 						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
@@ -772,108 +917,1135 @@ var _ = Describe("Parser", func() {
 					actual, err := parser.Parse()
 					Expect(err).ToNot(BeNil())
 					Expect(actual).To(BeNil())
-					Expect(err.Error()).To(ContainSubstring("expect variable name"))
+					Expect(err.Error()).To(ContainSubstring("invalid assignment target"))
 				})
 			})
 		})
+		Describe("Lists", func() {
+			When("its a list literal with a few elements", func() {
+				It("returns a list expression", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.COMMA, Lexeme: ",", Line: 1},
+						{Type: lexer.STRING, Literal: "two", Lexeme: "\"two\"", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.List{Elements: []expr.Expr{
+							expr.Primary{Value: 1},
+							expr.Primary{Value: "two"},
+						}},
+					}))
+				})
+			})
 
-		Describe("Block", func() {
-			When("its a list with an empty block", func() {
-				It("returns a block statement with no statements", func() {
+			When("its an empty list literal", func() {
+				It("returns a list expression with no elements", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
-						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
 						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
-					Expect(actual[0]).To(Equal(stmt.Block{
-						Statements: nil,
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.List{Elements: []expr.Expr{}},
 					}))
 				})
 			})
 
-			When("its a list with a block containing a single statement", func() {
-				It("returns a block statement with one statement", func() {
+			When("its a list literal missing the closing bracket", func() {
+				It("returns an error", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
-						{Type: lexer.PRINT, Lexeme: "print", Line: 2},
-						{Type: lexer.STRING, Literal: "test", Lexeme: "\"test\"", Line: 2},
-						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
-						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 3},
-						{Type: lexer.EOF, Lexeme: "EOF", Line: 3},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					_, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect ']' after list elements"))
+				})
+			})
+
+			When("its a chain of indexes", func() {
+				It("returns nested index expressions", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IDENTIFIER, Lexeme: "grid", Line: 1},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.NUMBER, Literal: 0, Lexeme: "0", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
-					Expect(actual[0]).To(Equal(stmt.Block{
-						Statements: []stmt.Stmt{
-							stmt.Print{
-								Expression: expr.Primary{Value: "test"},
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Index{
+							Object: expr.Index{
+								Object:  &expr.Variable{Name: tokens[0]},
+								Bracket: tokens[3],
+								Index:   expr.Primary{Value: 0},
 							},
+							Bracket: tokens[6],
+							Index:   expr.Primary{Value: 1},
 						},
 					}))
 				})
 			})
 
-			When("its a list with a block containing multiple statements", func() {
-				It("returns a block statement with multiple statements", func() {
+			When("its an assignment to an index", func() {
+				It("returns an index set expression", func() {
 					tokens := []lexer.Token{
-						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
-						{Type: lexer.PRINT, Lexeme: "print", Line: 2},
-						{Type: lexer.STRING, Literal: "test1", Lexeme: "\"test1\"", Line: 2},
-						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
-						{Type: lexer.PRINT, Lexeme: "print", Line: 3},
-						{Type: lexer.STRING, Literal: "test2", Lexeme: "\"test2\"", Line: 3},
-						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 3},
-						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 4},
-						{Type: lexer.EOF, Lexeme: "EOF", Line: 4},
+						{Type: lexer.IDENTIFIER, Lexeme: "xs", Line: 1},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.MINUS, Lexeme: "-", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
+						{Type: lexer.NUMBER, Literal: 5, Lexeme: "5", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
-					Expect(actual[0]).To(Equal(stmt.Block{
-						Statements: []stmt.Stmt{
-							stmt.Print{
-								Expression: expr.Primary{Value: "test1"},
-							},
-							stmt.Print{
-								Expression: expr.Primary{Value: "test2"},
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.IndexSet{
+							Object:  &expr.Variable{Name: tokens[0]},
+							Bracket: tokens[4],
+							Index: expr.Unary{
+								Operator: tokens[2],
+								Right:    expr.Primary{Value: 1},
 							},
+							Value: expr.Primary{Value: 5},
 						},
 					}))
 				})
 			})
-
-			When("its a list with a block containing nested blocks", func() {
-				It("returns a block statement with nested block statements", func() {
+		})
+		Describe("Maps", func() {
+			When("its a map literal in expression position", func() {
+				It("returns a map expression with its entries in order", func() {
 					tokens := []lexer.Token{
+						{Type: lexer.VAR, Lexeme: "var", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "m", Line: 1},
+						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
 						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
-						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 2},
-						{Type: lexer.PRINT, Lexeme: "print", Line: 3},
-						{Type: lexer.STRING, Literal: "nested", Lexeme: "\"nested\"", Line: 3},
-						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 3},
-						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 4},
-						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 5},
-						{Type: lexer.EOF, Lexeme: "EOF", Line: 5},
+						{Type: lexer.STRING, Literal: "a", Lexeme: "\"a\"", Line: 1},
+						{Type: lexer.COLON, Lexeme: ":", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.COMMA, Lexeme: ",", Line: 1},
+						{Type: lexer.STRING, Literal: "b", Lexeme: "\"b\"", Line: 1},
+						{Type: lexer.COLON, Lexeme: ":", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
 					}
 					parser := New(tokens)
 					actual, err := parser.Parse()
 					Expect(err).To(BeNil())
-					Expect(actual[0]).To(Equal(stmt.Block{
-						Statements: []stmt.Stmt{
-							stmt.Block{
-								Statements: []stmt.Stmt{
-									stmt.Print{
-										Expression: expr.Primary{Value: "nested"},
-									},
-								},
-							},
+					Expect(actual[0]).To(Equal(stmt.Var{
+						Name: tokens[1],
+						Initializer: expr.Map{
+							Brace:  tokens[3],
+							Keys:   []expr.Expr{expr.Primary{Value: "a"}, expr.Primary{Value: "b"}},
+							Values: []expr.Expr{expr.Primary{Value: 1}, expr.Primary{Value: 2}},
 						},
 					}))
 				})
 			})
+
+			When("its a brace at the start of a statement", func() {
+				It("still returns a block", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Block{}))
+				})
+			})
+
+			When("its a map entry missing a colon", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.STRING, Literal: "a", Lexeme: "\"a\"", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					_, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect ':' after map key"))
+				})
+			})
+		})
+		Describe("Slices", func() {
+			When("its a slice with both bounds", func() {
+				It("returns a slice expression", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IDENTIFIER, Lexeme: "s", Line: 1},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.COLON, Lexeme: ":", Line: 1},
+						{Type: lexer.NUMBER, Literal: 3, Lexeme: "3", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Slice{
+							Object:  &expr.Variable{Name: tokens[0]},
+							Bracket: tokens[5],
+							Start:   expr.Primary{Value: 1},
+							End:     expr.Primary{Value: 3},
+						},
+					}))
+				})
+			})
+
+			When("its a slice with no bounds", func() {
+				It("returns a slice expression with nil bounds", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IDENTIFIER, Lexeme: "s", Line: 1},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.COLON, Lexeme: ":", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Slice{
+							Object:  &expr.Variable{Name: tokens[0]},
+							Bracket: tokens[3],
+						},
+					}))
+				})
+			})
+
+			When("its an assignment to a slice", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IDENTIFIER, Lexeme: "s", Line: 1},
+						{Type: lexer.LEFT_BRACKET, Lexeme: "[", Line: 1},
+						{Type: lexer.COLON, Lexeme: ":", Line: 1},
+						{Type: lexer.RIGHT_BRACKET, Lexeme: "]", Line: 1},
+						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					_, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(ContainSubstring("invalid assignment target"))
+				})
+			})
+		})
+		Describe("Interpolation", func() {
+			When("its a string with two embedded expressions", func() {
+				It("returns an interpolation of the pieces and expressions in order", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.INTERPOLATION, Literal: "hi ", Lexeme: "\"hi ${", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "name", Line: 1},
+						{Type: lexer.INTERPOLATION, Literal: ", ", Lexeme: "}, ${", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.STRING, Literal: "!", Lexeme: "}!\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Interpolation{Parts: []expr.Expr{
+							expr.Primary{Value: "hi "},
+							&expr.Variable{Name: tokens[1]},
+							expr.Primary{Value: ", "},
+							expr.Primary{Value: 1},
+							expr.Primary{Value: "!"},
+						}},
+					}))
+				})
+			})
+
+			When("its an interpolation whose expression doesn't end at the closing brace", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.INTERPOLATION, Literal: "", Lexeme: "\"${", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.NUMBER, Literal: 2, Lexeme: "2", Line: 1},
+						{Type: lexer.STRING, Literal: "", Lexeme: "}\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					_, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect '}' after interpolated expression"))
+				})
+			})
+		})
+	})
+
+	Describe("Statements", func() {
+		Describe("Print", func() {
+			When("its a list with a print token and a string", func() {
+				It("returns a print statement with a string expression", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "test", Lexeme: "\"test\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Print{
+						Expression: expr.Primary{Value: "test"},
+					}))
+				})
+			})
+
+			When("its a list with a print token and string but without a semicolon", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "test", Lexeme: "\"test\"", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					_, err := parser.Parse()
+					Expect(err.Error()).To(ContainSubstring("reached end of file"))
+				})
+			})
+		})
+
+		Describe("Var", func() {
+			When("its a list with a var and an identifier", func() {
+				It("returns a var declaration", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.VAR, Lexeme: "var", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Var{
+						Name: lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
+					}))
+				})
+			})
+
+			When("its a list with a var, identifier and initializer", func() {
+				It("returns a var declaration with an initializer", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.VAR, Lexeme: "var", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
+						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Var{
+						Name:        lexer.Token{Type: lexer.IDENTIFIER, Lexeme: "foo", Line: 1},
+						Initializer: expr.Primary{Value: true},
+					}))
+				})
+			})
+
+			When("its a list with a var and a non-identifier token", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.VAR, Lexeme: "var", Line: 1},
+						{Type: lexer.NUMBER, Lexeme: "123", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect variable name"))
+				})
+			})
+		})
+
+		Describe("Block", func() {
+			When("its a list with an empty block", func() {
+				It("returns a block statement with no statements", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Block{
+						Statements: nil,
+					}))
+				})
+			})
+
+			When("its a list with a block containing a single statement", func() {
+				It("returns a block statement with one statement", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 2},
+						{Type: lexer.STRING, Literal: "test", Lexeme: "\"test\"", Line: 2},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 3},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 3},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Block{
+						Statements: []stmt.Stmt{
+							stmt.Print{
+								Expression: expr.Primary{Value: "test"},
+							},
+						},
+					}))
+				})
+			})
+
+			When("its a list with a block containing multiple statements", func() {
+				It("returns a block statement with multiple statements", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 2},
+						{Type: lexer.STRING, Literal: "test1", Lexeme: "\"test1\"", Line: 2},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 3},
+						{Type: lexer.STRING, Literal: "test2", Lexeme: "\"test2\"", Line: 3},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 3},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 4},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 4},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Block{
+						Statements: []stmt.Stmt{
+							stmt.Print{
+								Expression: expr.Primary{Value: "test1"},
+							},
+							stmt.Print{
+								Expression: expr.Primary{Value: "test2"},
+							},
+						},
+					}))
+				})
+			})
+
+			When("its a list with a block containing nested blocks", func() {
+				It("returns a block statement with nested block statements", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 2},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 3},
+						{Type: lexer.STRING, Literal: "nested", Lexeme: "\"nested\"", Line: 3},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 3},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 4},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 5},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 5},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Block{
+						Statements: []stmt.Stmt{
+							stmt.Block{
+								Statements: []stmt.Stmt{
+									stmt.Print{
+										Expression: expr.Primary{Value: "nested"},
+									},
+								},
+							},
+						},
+					}))
+				})
+			})
+		})
+
+		Describe("If", func() {
+			When("its a list with an if statement without an else", func() {
+				It("returns an if statement with a nil else branch", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IF, Lexeme: "if", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "then", Lexeme: "\"then\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.If{
+						Condition:  expr.Primary{Value: true},
+						ThenBranch: stmt.Print{Expression: expr.Primary{Value: "then"}},
+					}))
+				})
+			})
+
+			When("its a list with an if statement and an else", func() {
+				It("returns an if statement with both branches", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IF, Lexeme: "if", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.FALSE, Lexeme: "false", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 2},
+						{Type: lexer.STRING, Literal: "then", Lexeme: "\"then\"", Line: 2},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 3},
+						{Type: lexer.ELSE, Lexeme: "else", Line: 3},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 3},
+						{Type: lexer.STRING, Literal: "else", Lexeme: "\"else\"", Line: 3},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 3},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 3},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.If{
+						Condition: expr.Primary{Value: false},
+						ThenBranch: stmt.Block{
+							Statements: []stmt.Stmt{
+								stmt.Print{Expression: expr.Primary{Value: "then"}},
+							},
+						},
+						ElseBranch: stmt.Print{Expression: expr.Primary{Value: "else"}},
+					}))
+				})
+			})
+
+			When("its a list with a nested if statement and a single else", func() {
+				It("binds the else to the nearest if", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IF, Lexeme: "if", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.IF, Lexeme: "if", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.FALSE, Lexeme: "false", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "inner", Lexeme: "\"inner\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.ELSE, Lexeme: "else", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "else", Lexeme: "\"else\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual).To(HaveLen(1))
+					Expect(actual[0]).To(Equal(stmt.If{
+						Condition: expr.Primary{Value: true},
+						ThenBranch: stmt.If{
+							Condition:  expr.Primary{Value: false},
+							ThenBranch: stmt.Print{Expression: expr.Primary{Value: "inner"}},
+							ElseBranch: stmt.Print{Expression: expr.Primary{Value: "else"}},
+						},
+					}))
+				})
+			})
+
+			When("its a list with an if statement missing the opening parenthesis", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.IF, Lexeme: "if", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "then", Lexeme: "\"then\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect '(' after 'if'"))
+				})
+			})
+		})
+
+		Describe("While", func() {
+			When("its a list with a while statement", func() {
+				It("returns a while statement", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.WHILE, Lexeme: "while", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "loop", Lexeme: "\"loop\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.While{
+						Condition: expr.Primary{Value: true},
+						Body:      stmt.Print{Expression: expr.Primary{Value: "loop"}},
+					}))
+				})
+			})
+
+			When("its a list with a while statement missing the closing parenthesis", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.WHILE, Lexeme: "while", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "loop", Lexeme: "\"loop\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect ')' after while condition"))
+				})
+			})
+		})
+
+		Describe("For", func() {
+			When("its a list with a for statement with all clauses", func() {
+				It("returns a block with the initializer and a desugared while loop", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.FOR, Lexeme: "for", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.VAR, Lexeme: "var", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "i", Line: 1},
+						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
+						{Type: lexer.NUMBER, Literal: 0, Lexeme: "0", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "i", Line: 1},
+						{Type: lexer.LESS, Lexeme: "<", Line: 1},
+						{Type: lexer.NUMBER, Literal: 3, Lexeme: "3", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "i", Line: 1},
+						{Type: lexer.EQUAL, Lexeme: "=", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "i", Line: 1},
+						{Type: lexer.PLUS, Lexeme: "+", Line: 1},
+						{Type: lexer.NUMBER, Literal: 1, Lexeme: "1", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "i", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Block{
+						Statements: []stmt.Stmt{
+							stmt.Var{Name: tokens[3], Initializer: expr.Primary{Value: 0}},
+							stmt.While{
+								Condition: expr.Binary{
+									Left:     &expr.Variable{Name: tokens[7]},
+									Operator: tokens[8],
+									Right:    expr.Primary{Value: 3},
+								},
+								Body: stmt.Print{Expression: &expr.Variable{Name: tokens[18]}},
+								Increment: &expr.Assign{
+									Name: tokens[11],
+									Value: expr.Binary{
+										Left:     &expr.Variable{Name: tokens[13]},
+										Operator: tokens[14],
+										Right:    expr.Primary{Value: 1},
+									},
+								},
+							},
+						},
+					}))
+				})
+			})
+
+			When("its a list with a for statement with no clauses", func() {
+				It("returns a while loop with a true condition", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.FOR, Lexeme: "for", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "loop", Lexeme: "\"loop\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.While{
+						Condition: expr.Primary{Value: true},
+						Body:      stmt.Print{Expression: expr.Primary{Value: "loop"}},
+					}))
+				})
+			})
+
+			When("its a list with a for statement missing the condition semicolon", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.FOR, Lexeme: "for", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.PRINT, Lexeme: "print", Line: 1},
+						{Type: lexer.STRING, Literal: "loop", Lexeme: "\"loop\"", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect ';' after loop condition"))
+				})
+			})
+		})
+
+		Describe("Break and Continue", func() {
+			When("its a list with a break inside a while body", func() {
+				It("returns a while statement containing a break statement", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.WHILE, Lexeme: "while", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.BREAK, Lexeme: "break", Line: 2},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 3},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 3},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.While{
+						Condition: expr.Primary{Value: true},
+						Body: stmt.Block{
+							Statements: []stmt.Stmt{stmt.Break{Keyword: tokens[5]}},
+						},
+					}))
+				})
+			})
+
+			When("its a list with a continue inside a for body", func() {
+				It("returns a while statement containing a continue statement", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.FOR, Lexeme: "for", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.CONTINUE, Lexeme: "continue", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.While{
+						Condition: expr.Primary{Value: true},
+						Body:      stmt.Continue{Keyword: tokens[5]},
+					}))
+				})
+			})
+
+			When("its a list with a break outside of a loop", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.BREAK, Lexeme: "break", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("'break' must be inside a loop"))
+				})
+			})
+
+			When("its a list with a continue after a loop has ended", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.WHILE, Lexeme: "while", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.FALSE, Lexeme: "false", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.CONTINUE, Lexeme: "continue", Line: 2},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 2},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("'continue' must be inside a loop"))
+				})
+			})
+		})
+
+		Describe("Function", func() {
+			When("its a list with a function declaration", func() {
+				It("returns a function declaration with its params and body", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.FUNC, Lexeme: "fn", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "add", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "a", Line: 1},
+						{Type: lexer.COMMA, Lexeme: ",", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "b", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.RETURN, Lexeme: "return", Line: 2},
+						{Type: lexer.IDENTIFIER, Lexeme: "a", Line: 2},
+						{Type: lexer.PLUS, Lexeme: "+", Line: 2},
+						{Type: lexer.IDENTIFIER, Lexeme: "b", Line: 2},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 3},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 3},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Function{
+						Name:   tokens[1],
+						Params: []lexer.Token{tokens[3], tokens[5]},
+						Body: []stmt.Stmt{
+							stmt.Return{
+								Keyword: tokens[8],
+								Value: expr.Binary{
+									Left:     &expr.Variable{Name: tokens[9]},
+									Operator: tokens[10],
+									Right:    &expr.Variable{Name: tokens[11]},
+								},
+							},
+						},
+					}))
+				})
+			})
+
+			When("its a list with a function declaration missing its name", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.FUNC, Lexeme: "fn", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect function name"))
+				})
+			})
+
+			When("its a list with a return outside of a function", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.RETURN, Lexeme: "return", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("'return' must be inside a function"))
+				})
+			})
+
+			When("its a list with a break in a function declared inside a loop", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.WHILE, Lexeme: "while", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.TRUE, Lexeme: "true", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.FUNC, Lexeme: "fn", Line: 2},
+						{Type: lexer.IDENTIFIER, Lexeme: "f", Line: 2},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 2},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 2},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 2},
+						{Type: lexer.BREAK, Lexeme: "break", Line: 2},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 2},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 2},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 3},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 3},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("'break' must be inside a loop"))
+				})
+			})
+		})
+
+		Describe("Class", func() {
+			When("its a list with a class declaration with methods", func() {
+				It("returns a class declaration holding the methods", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.CLASS, Lexeme: "class", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "Node", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "init", Line: 2},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 2},
+						{Type: lexer.IDENTIFIER, Lexeme: "value", Line: 2},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 2},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 2},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 2},
+						{Type: lexer.IDENTIFIER, Lexeme: "get", Line: 3},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 3},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 3},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 3},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 3},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 4},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 4},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Class{
+						Name: tokens[1],
+						Methods: []stmt.Function{
+							{Name: tokens[3], Params: []lexer.Token{tokens[5]}},
+							{Name: tokens[9], Params: []lexer.Token{}},
+						},
+					}))
+				})
+			})
+
+			When("its a list with a class declaration missing its closing brace", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.CLASS, Lexeme: "class", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "Node", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect '}' after class body"))
+				})
+			})
+		})
+
+		Describe("Inheritance", func() {
+			When("its a list with a class declaration that has a superclass", func() {
+				It("returns a class declaration with the superclass variable", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.CLASS, Lexeme: "class", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "Dog", Line: 1},
+						{Type: lexer.LESS, Lexeme: "<", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "Animal", Line: 1},
+						{Type: lexer.LEFT_BRACE, Lexeme: "{", Line: 1},
+						{Type: lexer.RIGHT_BRACE, Lexeme: "}", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Class{
+						Name:       tokens[1],
+						Superclass: &expr.Variable{Name: tokens[3]},
+						Methods:    []stmt.Function{},
+					}))
+				})
+			})
+
+			When("its a list with a super method call", func() {
+				It("returns a call on a super expression", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.SUPER, Lexeme: "super", Line: 1},
+						{Type: lexer.DOT, Lexeme: ".", Line: 1},
+						{Type: lexer.IDENTIFIER, Lexeme: "speak", Line: 1},
+						{Type: lexer.LEFT_PAREN, Lexeme: "(", Line: 1},
+						{Type: lexer.RIGHT_PAREN, Lexeme: ")", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).To(BeNil())
+					Expect(actual[0]).To(Equal(stmt.Expression{
+						Expression: expr.Call{
+							Callee:    &expr.Super{Keyword: tokens[0], Method: tokens[2]},
+							Paren:     tokens[4],
+							Arguments: []expr.Expr{},
+						},
+					}))
+				})
+			})
+
+			When("its a list with super but no method name", func() {
+				It("returns an error", func() {
+					tokens := []lexer.Token{
+						{Type: lexer.SUPER, Lexeme: "super", Line: 1},
+						{Type: lexer.SEMICOLON, Lexeme: ";", Line: 1},
+						{Type: lexer.EOF, Lexeme: "EOF", Line: 1},
+					}
+					parser := New(tokens)
+					actual, err := parser.Parse()
+					Expect(err).ToNot(BeNil())
+					Expect(actual).To(BeNil())
+					Expect(err.Error()).To(ContainSubstring("expect '.' after 'super'"))
+				})
+			})
+		})
+	})
+
+	Describe("Errors", func() {
+		parse := func(source string) ([]stmt.Stmt, error) {
+			tokens, err := lexer.New(source).Scan()
+			Expect(err).To(BeNil())
+			return New(tokens).Parse()
+		}
+
+		When("there are syntax errors in several statements", func() {
+			It("reports every one of them in a single pass", func() {
+				actual, err := parse("var = 1;\nprint 1 +;\nvar b = 2;\nbreak;\n")
+				Expect(actual).To(BeNil())
+				var errs diagnostics.List
+				Expect(errors.As(err, &errs)).To(BeTrue())
+				Expect(errs).To(HaveLen(3))
+				Expect(errs[0].Message).To(Equal("expect variable name"))
+				Expect(errs[0].Span.Line).To(Equal(int64(1)))
+				Expect(errs[1].Message).To(Equal("; expected expression"))
+				Expect(errs[1].Span.Line).To(Equal(int64(2)))
+				Expect(errs[2].Message).To(Equal("'break' must be inside a loop"))
+				Expect(errs[2].Span.Line).To(Equal(int64(4)))
+			})
+		})
+
+		When("an error happens in the middle of a statement", func() {
+			It("skips to the next statement keyword instead of reporting the leftovers", func() {
+				_, err := parse("var a = (1 + 2 print a;\nvar b = 3;\n")
+				var errs diagnostics.List
+				Expect(errors.As(err, &errs)).To(BeTrue())
+				Expect(errs).To(HaveLen(1))
+				Expect(errs[0].Span.Column).To(Equal(int64(16)))
+			})
+		})
+
+		When("errors are inside of function and loop bodies", func() {
+			It("reports only the real mistakes", func() {
+				_, err := parse(`fn f(n) {
+  var = 1;
+  while (n > 0) {
+    n = n - ;
+    break;
+  }
+  return n;
+}
+print f(3) +;
+print 2;
+`)
+				var errs diagnostics.List
+				Expect(errors.As(err, &errs)).To(BeTrue())
+				Expect(errs).To(HaveLen(3))
+				Expect(errs[0].Message).To(Equal("expect variable name"))
+				Expect(errs[0].Span.Line).To(Equal(int64(2)))
+				Expect(errs[1].Message).To(Equal("; expected expression"))
+				Expect(errs[1].Span.Line).To(Equal(int64(4)))
+				Expect(errs[2].Message).To(Equal("; expected expression"))
+				Expect(errs[2].Span.Line).To(Equal(int64(9)))
+			})
+		})
+
+		DescribeTable("recovers without reporting the code around the mistake",
+			func(source string, messages ...string) {
+				_, err := parse(source)
+				var errs diagnostics.List
+				Expect(errors.As(err, &errs)).To(BeTrue())
+				actual := []string{}
+				for _, e := range errs {
+					actual = append(actual, e.Message)
+				}
+				Expect(actual).To(Equal(messages))
+			},
+			Entry("a mistake right before the closing brace", "fn f() { return 1 + } print 2 +;",
+				"} expected expression", "; expected expression"),
+			Entry("a mistake in a condition skips the whole block after it", "if (1 +) { print 1; } print 2 +;",
+				") expected expression", "; expected expression"),
+			Entry("a stray closing brace at the top level", "} print 1 +;",
+				"} expected expression", "; expected expression"),
+			Entry("a nested block inside of a loop", "while (true) { { print 1 + } break; }",
+				"} expected expression"),
+			Entry("a block that is never closed", "{ var = 1;",
+				"expect variable name", "expect closing '}' after block: reached end of file"),
+		)
+
+		When("an assignment has an invalid target", func() {
+			It("points at the equals sign", func() {
+				_, err := parse("1 = 2;")
+				var errs diagnostics.List
+				Expect(errors.As(err, &errs)).To(BeTrue())
+				Expect(errs).To(HaveLen(1))
+				Expect(errs[0].Span.Column).To(Equal(int64(3)))
+				Expect(err.Error()).To(Equal("line 1, column 3: invalid assignment target"))
+			})
 		})
 	})
 })
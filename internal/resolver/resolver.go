@@ -0,0 +1,518 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/maxcelant/kiwi/internal/diagnostics"
+	"github.com/maxcelant/kiwi/internal/expr"
+	"github.com/maxcelant/kiwi/internal/lexer"
+	"github.com/maxcelant/kiwi/internal/stmt"
+)
+
+// Maps every local variable access to the number of scopes between where it is
+// used and where it was declared. Accesses that aren't in here are globals.
+type Locals map[expr.Expr]int
+
+// Tracks what kind of body we are inside of, so that statements like 'return'
+// and expressions like 'this' can be rejected where they make no sense
+type functionType int
+
+const (
+	noFunction functionType = iota
+	function
+	method
+	initializer
+)
+
+type classType int
+
+const (
+	noClass classType = iota
+	class
+	subclass
+)
+
+// The resolver walks the statements once before they get interpreted, so that each
+// variable is bound to the declaration that was in scope where it was written,
+// not to whatever happens to be in scope when the code eventually runs.
+type Resolver struct {
+	// Each scope maps a name to whether its initializer has finished resolving.
+	// The global scope is never tracked, since globals can be redeclared freely.
+	scopes          []map[string]bool
+	locals          Locals
+	currentFunction functionType
+	currentClass    classType
+}
+
+func New() *Resolver {
+	return &Resolver{
+		scopes:          []map[string]bool{},
+		locals:          Locals{},
+		currentFunction: noFunction,
+		currentClass:    noClass,
+	}
+}
+
+func (r *Resolver) Resolve(stmts []stmt.Stmt) (Locals, error) {
+	for _, st := range stmts {
+		if err := r.resolveStmt(st); err != nil {
+			return nil, err
+		}
+	}
+	return r.locals, nil
+}
+
+func (r *Resolver) error(token lexer.Token, message string) *diagnostics.Diagnostic {
+	return diagnostics.New(diagnostics.Error, token.Span(), message)
+}
+
+func (r *Resolver) resolveStmt(st stmt.Stmt) error {
+	return st.Accept(r)
+}
+
+func (r *Resolver) resolveExpr(ex expr.Expr) error {
+	_, err := ex.Accept(r)
+	return err
+}
+
+func (r *Resolver) resolveStmts(stmts []stmt.Stmt) error {
+	for _, st := range stmts {
+		if err := r.resolveStmt(st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, map[string]bool{})
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// Adds the name to the innermost scope, but marks it as not ready yet so that
+// its own initializer can't read it
+func (r *Resolver) declare(name lexer.Token) error {
+	if len(r.scopes) == 0 {
+		return nil
+	}
+	scope := r.scopes[len(r.scopes)-1]
+	if _, ok := scope[name.Lexeme]; ok {
+		return r.error(name, fmt.Sprintf("already a variable named '%s' in this scope", name.Lexeme))
+	}
+	scope[name.Lexeme] = false
+	return nil
+}
+
+func (r *Resolver) define(name lexer.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	r.scopes[len(r.scopes)-1][name.Lexeme] = true
+}
+
+// Walks from the innermost scope outwards and records how far away the name was
+// found. If it is never found, we leave it unresolved and assume it's a global.
+func (r *Resolver) resolveLocal(ex expr.Expr, name lexer.Token) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name.Lexeme]; ok {
+			r.locals[ex] = len(r.scopes) - 1 - i
+			return
+		}
+	}
+}
+
+func (r *Resolver) resolveFunction(fn stmt.Function, kind functionType) error {
+	enclosingFunction := r.currentFunction
+	r.currentFunction = kind
+	r.beginScope()
+	defer func() {
+		r.endScope()
+		r.currentFunction = enclosingFunction
+	}()
+	for _, param := range fn.Params {
+		if err := r.declare(param); err != nil {
+			return err
+		}
+		r.define(param)
+	}
+	return r.resolveStmts(fn.Body)
+}
+
+func (r *Resolver) VisitBlockStatement(st stmt.Stmt) error {
+	block, ok := st.(stmt.Block)
+	if !ok {
+		return fmt.Errorf("not an block statement")
+	}
+	r.beginScope()
+	defer r.endScope()
+	return r.resolveStmts(block.Statements)
+}
+
+func (r *Resolver) VisitVarDeclaration(st stmt.Stmt) error {
+	varStmt, ok := st.(stmt.Var)
+	if !ok {
+		return fmt.Errorf("not a var declaration")
+	}
+	if err := r.declare(varStmt.Name); err != nil {
+		return err
+	}
+	if varStmt.Initializer != nil {
+		if err := r.resolveExpr(varStmt.Initializer); err != nil {
+			return err
+		}
+	}
+	r.define(varStmt.Name)
+	return nil
+}
+
+func (r *Resolver) VisitFunctionDeclaration(st stmt.Stmt) error {
+	fnStmt, ok := st.(stmt.Function)
+	if !ok {
+		return fmt.Errorf("not a function declaration")
+	}
+	// The name is defined before resolving the body so the function can call itself
+	if err := r.declare(fnStmt.Name); err != nil {
+		return err
+	}
+	r.define(fnStmt.Name)
+	return r.resolveFunction(fnStmt, function)
+}
+
+func (r *Resolver) VisitClassDeclaration(st stmt.Stmt) error {
+	classStmt, ok := st.(stmt.Class)
+	if !ok {
+		return fmt.Errorf("not a class declaration")
+	}
+	if err := r.declare(classStmt.Name); err != nil {
+		return err
+	}
+	r.define(classStmt.Name)
+
+	enclosingClass := r.currentClass
+	r.currentClass = class
+	defer func() { r.currentClass = enclosingClass }()
+
+	if classStmt.Superclass != nil {
+		if classStmt.Superclass.Name.Lexeme == classStmt.Name.Lexeme {
+			return r.error(classStmt.Superclass.Name, "a class can't inherit from itself")
+		}
+		r.currentClass = subclass
+		if err := r.resolveExpr(classStmt.Superclass); err != nil {
+			return err
+		}
+		// Subclass methods close over an extra scope holding 'super', which sits
+		// right outside of the scope holding 'this'
+		r.beginScope()
+		r.scopes[len(r.scopes)-1]["super"] = true
+		defer r.endScope()
+	}
+
+	// Methods are resolved inside of a scope that only holds 'this', which mirrors
+	// the environment that binding a method creates at runtime
+	r.beginScope()
+	r.scopes[len(r.scopes)-1]["this"] = true
+	defer r.endScope()
+
+	for _, m := range classStmt.Methods {
+		kind := method
+		if m.Name.Lexeme == "init" {
+			kind = initializer
+		}
+		if err := r.resolveFunction(m, kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) VisitExpressionStatement(st stmt.Stmt) error {
+	exprStmt, ok := st.(stmt.Expression)
+	if !ok {
+		return fmt.Errorf("not an expression statement")
+	}
+	return r.resolveExpr(exprStmt.Expression)
+}
+
+func (r *Resolver) VisitPrintStatement(st stmt.Stmt) error {
+	prntStmt, ok := st.(stmt.Print)
+	if !ok {
+		return fmt.Errorf("not a print statement")
+	}
+	return r.resolveExpr(prntStmt.Expression)
+}
+
+func (r *Resolver) VisitIfStatement(st stmt.Stmt) error {
+	ifStmt, ok := st.(stmt.If)
+	if !ok {
+		return fmt.Errorf("not an if statement")
+	}
+	if err := r.resolveExpr(ifStmt.Condition); err != nil {
+		return err
+	}
+	if err := r.resolveStmt(ifStmt.ThenBranch); err != nil {
+		return err
+	}
+	if ifStmt.ElseBranch != nil {
+		return r.resolveStmt(ifStmt.ElseBranch)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitWhileStatement(st stmt.Stmt) error {
+	whileStmt, ok := st.(stmt.While)
+	if !ok {
+		return fmt.Errorf("not a while statement")
+	}
+	if err := r.resolveExpr(whileStmt.Condition); err != nil {
+		return err
+	}
+	if err := r.resolveStmt(whileStmt.Body); err != nil {
+		return err
+	}
+	if whileStmt.Increment != nil {
+		return r.resolveExpr(whileStmt.Increment)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitBreakStatement(st stmt.Stmt) error {
+	return nil
+}
+
+func (r *Resolver) VisitContinueStatement(st stmt.Stmt) error {
+	return nil
+}
+
+func (r *Resolver) VisitReturnStatement(st stmt.Stmt) error {
+	returnStmt, ok := st.(stmt.Return)
+	if !ok {
+		return fmt.Errorf("not a return statement")
+	}
+	if returnStmt.Value != nil {
+		if r.currentFunction == initializer {
+			return r.error(returnStmt.Keyword, "can't return a value from an initializer")
+		}
+		return r.resolveExpr(returnStmt.Value)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitVariable(ex expr.Expr) (any, error) {
+	variable, ok := ex.(*expr.Variable)
+	if !ok {
+		return nil, fmt.Errorf("not a variable expression")
+	}
+	if len(r.scopes) > 0 {
+		if ready, ok := r.scopes[len(r.scopes)-1][variable.Name.Lexeme]; ok && !ready {
+			return nil, r.error(variable.Name, fmt.Sprintf("can't read local variable '%s' in its own initializer", variable.Name.Lexeme))
+		}
+	}
+	r.resolveLocal(variable, variable.Name)
+	return nil, nil
+}
+
+func (r *Resolver) VisitAssign(ex expr.Expr) (any, error) {
+	assign, ok := ex.(*expr.Assign)
+	if !ok {
+		return nil, fmt.Errorf("not an assign expression")
+	}
+	if err := r.resolveExpr(assign.Value); err != nil {
+		return nil, err
+	}
+	r.resolveLocal(assign, assign.Name)
+	return nil, nil
+}
+
+func (r *Resolver) VisitLogical(ex expr.Expr) (any, error) {
+	logical, ok := ex.(expr.Logical)
+	if !ok {
+		return nil, fmt.Errorf("not a logical expression")
+	}
+	if err := r.resolveExpr(logical.Left); err != nil {
+		return nil, err
+	}
+	return nil, r.resolveExpr(logical.Right)
+}
+
+func (r *Resolver) VisitBinary(ex expr.Expr) (any, error) {
+	binary, ok := ex.(expr.Binary)
+	if !ok {
+		return nil, fmt.Errorf("not a binary expression")
+	}
+	if err := r.resolveExpr(binary.Left); err != nil {
+		return nil, err
+	}
+	return nil, r.resolveExpr(binary.Right)
+}
+
+func (r *Resolver) VisitUnary(ex expr.Expr) (any, error) {
+	unary, ok := ex.(expr.Unary)
+	if !ok {
+		return nil, fmt.Errorf("not a unary expression")
+	}
+	return nil, r.resolveExpr(unary.Right)
+}
+
+func (r *Resolver) VisitCall(ex expr.Expr) (any, error) {
+	call, ok := ex.(expr.Call)
+	if !ok {
+		return nil, fmt.Errorf("not a call expression")
+	}
+	if err := r.resolveExpr(call.Callee); err != nil {
+		return nil, err
+	}
+	for _, arg := range call.Arguments {
+		if err := r.resolveExpr(arg); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitGet(ex expr.Expr) (any, error) {
+	get, ok := ex.(expr.Get)
+	if !ok {
+		return nil, fmt.Errorf("not a get expression")
+	}
+	// Property names are looked up dynamically, so only the object gets resolved
+	return nil, r.resolveExpr(get.Object)
+}
+
+func (r *Resolver) VisitSet(ex expr.Expr) (any, error) {
+	set, ok := ex.(expr.Set)
+	if !ok {
+		return nil, fmt.Errorf("not a set expression")
+	}
+	if err := r.resolveExpr(set.Value); err != nil {
+		return nil, err
+	}
+	return nil, r.resolveExpr(set.Object)
+}
+
+func (r *Resolver) VisitIndex(ex expr.Expr) (any, error) {
+	index, ok := ex.(expr.Index)
+	if !ok {
+		return nil, fmt.Errorf("not an index expression")
+	}
+	if err := r.resolveExpr(index.Object); err != nil {
+		return nil, err
+	}
+	return nil, r.resolveExpr(index.Index)
+}
+
+func (r *Resolver) VisitIndexSet(ex expr.Expr) (any, error) {
+	set, ok := ex.(expr.IndexSet)
+	if !ok {
+		return nil, fmt.Errorf("not an index set expression")
+	}
+	if err := r.resolveExpr(set.Value); err != nil {
+		return nil, err
+	}
+	if err := r.resolveExpr(set.Object); err != nil {
+		return nil, err
+	}
+	return nil, r.resolveExpr(set.Index)
+}
+
+func (r *Resolver) VisitSlice(ex expr.Expr) (any, error) {
+	slice, ok := ex.(expr.Slice)
+	if !ok {
+		return nil, fmt.Errorf("not a slice expression")
+	}
+	if err := r.resolveExpr(slice.Object); err != nil {
+		return nil, err
+	}
+	for _, bound := range []expr.Expr{slice.Start, slice.End} {
+		if bound == nil {
+			continue
+		}
+		if err := r.resolveExpr(bound); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitThis(ex expr.Expr) (any, error) {
+	this, ok := ex.(*expr.This)
+	if !ok {
+		return nil, fmt.Errorf("not a this expression")
+	}
+	if r.currentClass == noClass {
+		return nil, r.error(this.Keyword, "can't use 'this' outside of a class")
+	}
+	r.resolveLocal(this, this.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitSuper(ex expr.Expr) (any, error) {
+	super, ok := ex.(*expr.Super)
+	if !ok {
+		return nil, fmt.Errorf("not a super expression")
+	}
+	if r.currentClass == noClass {
+		return nil, r.error(super.Keyword, "can't use 'super' outside of a class")
+	}
+	if r.currentClass != subclass {
+		return nil, r.error(super.Keyword, "can't use 'super' in a class with no superclass")
+	}
+	r.resolveLocal(super, super.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitPrimary(ex expr.Expr) (any, error) {
+	return nil, nil
+}
+
+func (r *Resolver) VisitList(ex expr.Expr) (any, error) {
+	list, ok := ex.(expr.List)
+	if !ok {
+		return nil, fmt.Errorf("not a list expression")
+	}
+	for _, element := range list.Elements {
+		if err := r.resolveExpr(element); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitMap(ex expr.Expr) (any, error) {
+	m, ok := ex.(expr.Map)
+	if !ok {
+		return nil, fmt.Errorf("not a map expression")
+	}
+	for i := range m.Keys {
+		if err := r.resolveExpr(m.Keys[i]); err != nil {
+			return nil, err
+		}
+		if err := r.resolveExpr(m.Values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitInterpolation(ex expr.Expr) (any, error) {
+	interpolation, ok := ex.(expr.Interpolation)
+	if !ok {
+		return nil, fmt.Errorf("not an interpolation expression")
+	}
+	for _, part := range interpolation.Parts {
+		if err := r.resolveExpr(part); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitGrouping(ex expr.Expr) (any, error) {
+	grouping, ok := ex.(expr.Grouping)
+	if !ok {
+		return nil, fmt.Errorf("not a grouping expression")
+	}
+	return nil, r.resolveExpr(grouping.Expression)
+}
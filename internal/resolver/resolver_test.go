@@ -0,0 +1,351 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/maxcelant/kiwi/internal/diagnostics"
+	"github.com/maxcelant/kiwi/internal/expr"
+	"github.com/maxcelant/kiwi/internal/lexer"
+	"github.com/maxcelant/kiwi/internal/parser"
+	"github.com/maxcelant/kiwi/internal/stmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestResolver(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Resolver Suite")
+}
+
+var _ = Describe("Resolver", func() {
+	ident := func(name string) lexer.Token {
+		return lexer.Token{Type: lexer.IDENTIFIER, Lexeme: name, Line: 1}
+	}
+
+	Describe("Variables", func() {
+		When("a variable is read in the global scope", func() {
+			It("leaves it unresolved", func() {
+				read := &expr.Variable{Name: ident("a")}
+				stmts := []stmt.Stmt{
+					stmt.Var{Name: ident("a"), Initializer: expr.Primary{Value: 1}},
+					stmt.Print{Expression: read},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).ToNot(HaveKey(read))
+			})
+		})
+
+		When("a variable is read in the block it was declared in", func() {
+			It("resolves it to a depth of zero", func() {
+				read := &expr.Variable{Name: ident("a")}
+				stmts := []stmt.Stmt{
+					stmt.Block{Statements: []stmt.Stmt{
+						stmt.Var{Name: ident("a"), Initializer: expr.Primary{Value: 1}},
+						stmt.Print{Expression: read},
+					}},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).To(HaveKeyWithValue(read, 0))
+			})
+		})
+
+		When("a variable is assigned from nested blocks", func() {
+			It("resolves it to the number of scopes in between", func() {
+				assign := &expr.Assign{Name: ident("a"), Value: expr.Primary{Value: 2}}
+				stmts := []stmt.Stmt{
+					stmt.Block{Statements: []stmt.Stmt{
+						stmt.Var{Name: ident("a"), Initializer: expr.Primary{Value: 1}},
+						stmt.Block{Statements: []stmt.Stmt{
+							stmt.Block{Statements: []stmt.Stmt{
+								stmt.Expression{Expression: assign},
+							}},
+						}},
+					}},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).To(HaveKeyWithValue(assign, 2))
+			})
+		})
+
+		When("a variable is read before it is shadowed in the same block", func() {
+			It("resolves it to the outer declaration", func() {
+				read := &expr.Variable{Name: ident("a")}
+				stmts := []stmt.Stmt{
+					stmt.Block{Statements: []stmt.Stmt{
+						stmt.Var{Name: ident("a"), Initializer: expr.Primary{Value: 1}},
+						stmt.Block{Statements: []stmt.Stmt{
+							stmt.Print{Expression: read},
+							stmt.Var{Name: ident("a"), Initializer: expr.Primary{Value: 2}},
+						}},
+					}},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).To(HaveKeyWithValue(read, 1))
+			})
+		})
+
+		When("a local variable is read in its own initializer", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Block{Statements: []stmt.Stmt{
+						stmt.Var{Name: ident("a"), Initializer: &expr.Variable{Name: ident("a")}},
+					}},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("can't read local variable 'a' in its own initializer"))
+			})
+		})
+
+		When("a global variable is read in its own initializer", func() {
+			It("leaves it to the interpreter", func() {
+				stmts := []stmt.Stmt{
+					stmt.Var{Name: ident("a"), Initializer: &expr.Variable{Name: ident("a")}},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+			})
+		})
+
+		When("a local variable is redeclared in the same scope", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Block{Statements: []stmt.Stmt{
+						stmt.Var{Name: ident("a")},
+						stmt.Var{Name: ident("a")},
+					}},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("already a variable named 'a' in this scope"))
+			})
+		})
+
+		When("a global variable is redeclared", func() {
+			It("allows it", func() {
+				stmts := []stmt.Stmt{
+					stmt.Var{Name: ident("a")},
+					stmt.Var{Name: ident("a")},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Functions", func() {
+		When("a parameter is read in the function body", func() {
+			It("resolves it to the function's scope", func() {
+				read := &expr.Variable{Name: ident("n")}
+				stmts := []stmt.Stmt{
+					stmt.Function{
+						Name:   ident("f"),
+						Params: []lexer.Token{ident("n")},
+						Body:   []stmt.Stmt{stmt.Return{Value: read}},
+					},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).To(HaveKeyWithValue(read, 0))
+			})
+		})
+
+		When("a closure reads a local of its enclosing function", func() {
+			It("resolves it across the function boundary", func() {
+				read := &expr.Variable{Name: ident("count")}
+				stmts := []stmt.Stmt{
+					stmt.Function{
+						Name:   ident("outer"),
+						Params: []lexer.Token{},
+						Body: []stmt.Stmt{
+							stmt.Var{Name: ident("count"), Initializer: expr.Primary{Value: 0}},
+							stmt.Function{
+								Name:   ident("inner"),
+								Params: []lexer.Token{},
+								Body:   []stmt.Stmt{stmt.Return{Value: read}},
+							},
+						},
+					},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).To(HaveKeyWithValue(read, 1))
+			})
+		})
+
+		When("a function has two parameters with the same name", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Function{
+						Name:   ident("f"),
+						Params: []lexer.Token{ident("a"), ident("a")},
+					},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("already a variable named 'a' in this scope"))
+			})
+		})
+	})
+
+	Describe("Classes", func() {
+		When("this is used inside of a method", func() {
+			It("resolves it to the scope surrounding the method", func() {
+				this := &expr.This{Keyword: lexer.Token{Type: lexer.THIS, Lexeme: "this", Line: 1}}
+				stmts := []stmt.Stmt{
+					stmt.Class{
+						Name: ident("Node"),
+						Methods: []stmt.Function{
+							{Name: ident("self"), Params: []lexer.Token{}, Body: []stmt.Stmt{stmt.Return{Value: this}}},
+						},
+					},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).To(HaveKeyWithValue(this, 1))
+			})
+		})
+
+		When("this is used outside of a class", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Function{
+						Name:   ident("f"),
+						Params: []lexer.Token{},
+						Body: []stmt.Stmt{
+							stmt.Return{Value: &expr.This{Keyword: lexer.Token{Type: lexer.THIS, Lexeme: "this", Line: 1}}},
+						},
+					},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("can't use 'this' outside of a class"))
+			})
+		})
+
+		When("an initializer returns a value", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Class{
+						Name: ident("Node"),
+						Methods: []stmt.Function{
+							{Name: ident("init"), Params: []lexer.Token{}, Body: []stmt.Stmt{stmt.Return{Value: expr.Primary{Value: 1}}}},
+						},
+					},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("can't return a value from an initializer"))
+			})
+		})
+
+		When("an initializer returns without a value", func() {
+			It("allows it", func() {
+				stmts := []stmt.Stmt{
+					stmt.Class{
+						Name: ident("Node"),
+						Methods: []stmt.Function{
+							{Name: ident("init"), Params: []lexer.Token{}, Body: []stmt.Stmt{stmt.Return{}}},
+						},
+					},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Inheritance", func() {
+		superKeyword := lexer.Token{Type: lexer.SUPER, Lexeme: "super", Line: 1}
+
+		When("super is used inside of a subclass method", func() {
+			It("resolves it to the scope outside of 'this'", func() {
+				super := &expr.Super{Keyword: superKeyword, Method: ident("speak")}
+				stmts := []stmt.Stmt{
+					stmt.Class{Name: ident("Animal")},
+					stmt.Class{
+						Name:       ident("Dog"),
+						Superclass: &expr.Variable{Name: ident("Animal")},
+						Methods: []stmt.Function{
+							{Name: ident("speak"), Params: []lexer.Token{}, Body: []stmt.Stmt{stmt.Return{Value: super}}},
+						},
+					},
+				}
+				locals, err := New().Resolve(stmts)
+				Expect(err).To(BeNil())
+				Expect(locals).To(HaveKeyWithValue(super, 2))
+			})
+		})
+
+		When("a class inherits from itself", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Class{
+						Name:       ident("Loop"),
+						Superclass: &expr.Variable{Name: ident("Loop")},
+					},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("a class can't inherit from itself"))
+			})
+		})
+
+		When("super is used outside of a class", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Expression{Expression: &expr.Super{Keyword: superKeyword, Method: ident("speak")}},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("can't use 'super' outside of a class"))
+			})
+		})
+
+		When("super is used in a class without a superclass", func() {
+			It("returns an error", func() {
+				stmts := []stmt.Stmt{
+					stmt.Class{
+						Name: ident("Animal"),
+						Methods: []stmt.Function{
+							{Name: ident("speak"), Params: []lexer.Token{}, Body: []stmt.Stmt{
+								stmt.Return{Value: &expr.Super{Keyword: superKeyword, Method: ident("speak")}},
+							}},
+						},
+					},
+				}
+				_, err := New().Resolve(stmts)
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(ContainSubstring("can't use 'super' in a class with no superclass"))
+			})
+		})
+	})
+
+	Describe("Error locations", func() {
+		DescribeTable("points each error at the token that caused it",
+			func(source string, line, column int, message string) {
+				tokens, err := lexer.New(source).Scan()
+				Expect(err).To(BeNil())
+				stmts, err := parser.New(tokens).Parse()
+				Expect(err).To(BeNil())
+				_, err = New().Resolve(stmts)
+				list := diagnostics.From(err)
+				Expect(list).To(HaveLen(1))
+				Expect(list[0].Span.Line).To(Equal(int64(line)))
+				Expect(list[0].Span.Column).To(Equal(int64(column)))
+				Expect(list[0].Message).To(Equal(message))
+			},
+			Entry("a variable declared twice", "{\n  var a = 1;\n  var a = 2;\n}", 3, 7, "already a variable named 'a' in this scope"),
+			Entry("a variable read in its own initializer", "{ var a = a; }", 1, 11, "can't read local variable 'a' in its own initializer"),
+			Entry("a value returned from an initializer", "class A {\n  init() { return 1; }\n}", 2, 12, "can't return a value from an initializer"),
+			Entry("this outside of a class", "print this;", 1, 7, "can't use 'this' outside of a class"),
+			Entry("super outside of a class", "print super.x;", 1, 7, "can't use 'super' outside of a class"),
+			Entry("super without a superclass", "class A {\n  m() { return super.m(); }\n}", 2, 16, "can't use 'super' in a class with no superclass"),
+			Entry("a class inheriting from itself", "class A < A {}", 1, 11, "a class can't inherit from itself"),
+		)
+	})
+})
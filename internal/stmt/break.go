@@ -0,0 +1,15 @@
+package stmt
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type Break struct {
+	Keyword lexer.Token
+}
+
+func (b Break) Accept(v Visitor) error {
+	err := v.VisitBreakStatement(b)
+	if err != nil {
+		return err
+	}
+	return nil
+}
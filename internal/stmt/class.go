@@ -0,0 +1,20 @@
+package stmt
+
+import (
+	"github.com/maxcelant/kiwi/internal/expr"
+	"github.com/maxcelant/kiwi/internal/lexer"
+)
+
+type Class struct {
+	Name       lexer.Token
+	Superclass *expr.Variable // Can be null
+	Methods    []Function
+}
+
+func (c Class) Accept(v Visitor) error {
+	err := v.VisitClassDeclaration(c)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package stmt
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type Continue struct {
+	Keyword lexer.Token
+}
+
+func (c Continue) Accept(v Visitor) error {
+	err := v.VisitContinueStatement(c)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package stmt
+
+import "github.com/maxcelant/kiwi/internal/lexer"
+
+type Function struct {
+	Name   lexer.Token
+	Params []lexer.Token
+	Body   []Stmt
+}
+
+func (f Function) Accept(v Visitor) error {
+	err := v.VisitFunctionDeclaration(f)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -3,12 +3,12 @@ package stmt
 import "github.com/maxcelant/kiwi/internal/expr"
 
 type If struct {
-  Condition expr.Expr 
-  ThenBranch Stmt
-  ElseBranch Stmt
+	Condition  expr.Expr
+	ThenBranch Stmt
+	ElseBranch Stmt // Can be null
 }
 
-func (i *If) Accept(v Visitor) error {
+func (i If) Accept(v Visitor) error {
 	err := v.VisitIfStatement(i)
 	if err != nil {
 		return err
@@ -0,0 +1,19 @@
+package stmt
+
+import (
+	"github.com/maxcelant/kiwi/internal/expr"
+	"github.com/maxcelant/kiwi/internal/lexer"
+)
+
+type Return struct {
+	Keyword lexer.Token
+	Value   expr.Expr // Can be null
+}
+
+func (r Return) Accept(v Visitor) error {
+	err := v.VisitReturnStatement(r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,8 +1,14 @@
 package stmt
 
 type Visitor interface {
-  VisitIfStatement(Stmt) error
+	VisitIfStatement(Stmt) error
+	VisitWhileStatement(Stmt) error
+	VisitBreakStatement(Stmt) error
+	VisitContinueStatement(Stmt) error
+	VisitReturnStatement(Stmt) error
 	VisitBlockStatement(Stmt) error
+	VisitClassDeclaration(Stmt) error
+	VisitFunctionDeclaration(Stmt) error
 	VisitVarDeclaration(Stmt) error
 	VisitPrintStatement(Stmt) error
 	VisitExpressionStatement(Stmt) error
@@ -0,0 +1,17 @@
+package stmt
+
+import "github.com/maxcelant/kiwi/internal/expr"
+
+type While struct {
+	Condition expr.Expr
+	Body      Stmt
+	Increment expr.Expr // Can be null, only set by desugared for loops
+}
+
+func (w While) Accept(v Visitor) error {
+	err := v.VisitWhileStatement(w)
+	if err != nil {
+		return err
+	}
+	return nil
+}